@@ -0,0 +1,100 @@
+package resource
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// AliasScheme is the scheme conventionally mapped to an AliasResolver
+const AliasScheme = "alias"
+
+// AliasResolver maps short aliases onto full resource strings, using an alias file maintained outside the
+// application.  The alias file is a JSON object whose keys are aliases and whose values are resource strings,
+// and is itself a resource obtained by resolving Source through Loader.  A value such as "alias://db-config"
+// is looked up in the alias file, and the mapped resource string is resolved through Resolver.  Any scheme on
+// the value is ignored.
+//
+// The alias file is loaded on first use and retained until Reload is called.  An AliasResolver is safe for
+// concurrent use.
+type AliasResolver struct {
+	// Source is the resource string of the alias file.  This field is required.
+	Source string
+
+	// Loader is the Resolver used to obtain the alias file.  This field is required.
+	Loader Resolver
+
+	// Resolver is the optional Resolver for mapped resource strings.  If not supplied, Loader is used.
+	Resolver Resolver
+
+	lock    sync.RWMutex
+	aliases map[string]string
+}
+
+// Reload reads the alias file again, replacing the current aliases.  If the alias file cannot be
+// loaded or parsed, the current aliases are retained and the error is returned.
+func (ar *AliasResolver) Reload() error {
+	r, err := ar.Loader.Resolve(ar.Source)
+	if err != nil {
+		return err
+	}
+
+	rc, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	defer rc.Close()
+	var aliases map[string]string
+	if err := json.NewDecoder(rc).Decode(&aliases); err != nil {
+		return newResolveError(ar.Source, err)
+	}
+
+	// an alias file containing null is treated as empty, so that it is not reloaded on every resolution
+	if aliases == nil {
+		aliases = make(map[string]string)
+	}
+
+	ar.lock.Lock()
+	ar.aliases = aliases
+	ar.lock.Unlock()
+	return nil
+}
+
+// lookup returns the resource string for an alias, loading the alias file if necessary
+func (ar *AliasResolver) lookup(v, alias string) (string, error) {
+	ar.lock.RLock()
+	aliases := ar.aliases
+	ar.lock.RUnlock()
+
+	if aliases == nil {
+		if err := ar.Reload(); err != nil {
+			return "", err
+		}
+
+		ar.lock.RLock()
+		aliases = ar.aliases
+		ar.lock.RUnlock()
+	}
+
+	mapped, ok := aliases[alias]
+	if !ok {
+		return "", UnknownNameError{Value: v, Name: alias, Available: sortedNames(aliases)}
+	}
+
+	return mapped, nil
+}
+
+func (ar *AliasResolver) Resolve(v string) (Interface, error) {
+	_, alias := Split(v)
+	mapped, err := ar.lookup(v, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := ar.Resolver
+	if resolver == nil {
+		resolver = ar.Loader
+	}
+
+	return resolver.Resolve(mapped)
+}
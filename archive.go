@@ -0,0 +1,158 @@
+package resource
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ArchiveMemberSeparator separates an archive's resource string from the path of a member within it
+const ArchiveMemberSeparator = "!"
+
+// indexZip reads every regular file in a zip archive
+func indexZip(b []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		index[path.Clean(f.Name)] = content
+	}
+
+	return index, nil
+}
+
+// indexTar reads every regular file in a tar archive
+func indexTar(r io.Reader) (map[string][]byte, error) {
+	var (
+		tr    = tar.NewReader(r)
+		index = make(map[string][]byte)
+	)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return index, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		index[path.Clean(header.Name)] = content
+	}
+}
+
+// indexArchive detects the format of an archive and reads each of its members
+func indexArchive(b []byte) (map[string][]byte, error) {
+	switch {
+	case bytes.HasPrefix(b, []byte("PK\x03\x04")), bytes.HasPrefix(b, []byte("PK\x05\x06")):
+		return indexZip(b)
+
+	case bytes.HasPrefix(b, []byte{0x1F, 0x8B}):
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+
+		defer zr.Close()
+		return indexTar(zr)
+
+	default:
+		return indexTar(bytes.NewReader(b))
+	}
+}
+
+// ArchiveResolver resolves members of zip, tar, or gzipped tar archives.  Values have the form
+// "archive!path/to/member", where archive is any resource string resolvable by Resolver, including
+// in-memory resources such as "bytes://...".  Values without a member path are passed to Resolver as is.
+//
+// Each archive is loaded into memory and indexed the first time one of its members is resolved.
+// Subsequent resolutions against the same archive reuse that index.  The returned handles are in-memory.
+type ArchiveResolver struct {
+	// Resolver is used to resolve archives.  This field is required.
+	Resolver Resolver
+
+	lock     sync.Mutex
+	archives map[string]map[string][]byte
+}
+
+func (ar *ArchiveResolver) index(archive string) (map[string][]byte, error) {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+
+	if index, ok := ar.archives[archive]; ok {
+		return index, nil
+	}
+
+	r, err := ar.Resolver.Resolve(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if _, err := r.WriteTo(&b); err != nil {
+		return nil, err
+	}
+
+	index, err := indexArchive(b.Bytes())
+	if err != nil {
+		return nil, newResolveError(archive, err)
+	}
+
+	if ar.archives == nil {
+		ar.archives = make(map[string]map[string][]byte)
+	}
+
+	ar.archives[archive] = index
+	return index, nil
+}
+
+func (ar *ArchiveResolver) Resolve(v string) (Interface, error) {
+	i := strings.LastIndex(v, ArchiveMemberSeparator)
+	if i < 0 {
+		return ar.Resolver.Resolve(v)
+	}
+
+	index, err := ar.index(v[:i])
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := index[path.Clean(v[i+len(ArchiveMemberSeparator):])]
+	if !ok {
+		return nil, NotFoundError{Value: v}
+	}
+
+	return located{Bytes: Bytes(content), location: v}, nil
+}
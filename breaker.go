@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultBreakerThreshold is the number of consecutive failures that opens a CircuitBreaker's circuit for a host
+const DefaultBreakerThreshold = 5
+
+// DefaultBreakerCooldown is how long a CircuitBreaker's circuit stays open before a trial request is allowed
+const DefaultBreakerCooldown = 30 * time.Second
+
+// CircuitOpenError is returned by a CircuitBreaker when requests to a host are being short-circuited
+type CircuitOpenError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("Circuit open for host %s until %s", e.Host, e.Until.Format(time.RFC3339))
+}
+
+// circuit is the breaker state for a single host
+type circuit struct {
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// CircuitBreaker is an HTTPClient decorator that stops sending requests to hosts that keep failing.  Failures
+// are tracked per URL host:  either an error from the decorated client or a 5xx response counts as a failure, and
+// any other response resets the count.  Once Threshold consecutive failures occur, the circuit for that host opens
+// and requests fail immediately with a CircuitOpenError.  After Cooldown elapses, a single trial request is let
+// through.  If it succeeds the circuit closes, otherwise it opens again for another Cooldown.
+//
+// A CircuitBreaker is safe for concurrent use, and must not be copied after first use.
+type CircuitBreaker struct {
+	// Client is the decorated HTTPClient.  If not supplied, http.DefaultClient is used.
+	Client HTTPClient
+
+	// Threshold is the number of consecutive failures that opens a circuit.  If nonpositive,
+	// DefaultBreakerThreshold is used.
+	Threshold int
+
+	// Cooldown is how long a circuit stays open.  If nonpositive, DefaultBreakerCooldown is used.
+	Cooldown time.Duration
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+func (cb *CircuitBreaker) cooldown() time.Duration {
+	if cb.Cooldown > 0 {
+		return cb.Cooldown
+	}
+
+	return DefaultBreakerCooldown
+}
+
+// allow decides whether a request to host may proceed, and whether that request is a trial of an open circuit
+func (cb *CircuitBreaker) allow(host string) (trial bool, err error) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	c, ok := cb.circuits[host]
+	if !ok || c.openedAt.IsZero() {
+		return false, nil
+	}
+
+	until := c.openedAt.Add(cb.cooldown())
+	if c.trial || time.Now().Before(until) {
+		return false, CircuitOpenError{Host: host, Until: until}
+	}
+
+	c.trial = true
+	return true, nil
+}
+
+// record updates the circuit for host with the outcome of a request
+func (cb *CircuitBreaker) record(host string, trial, failed bool) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.circuits == nil {
+		cb.circuits = make(map[string]*circuit)
+	}
+
+	c, ok := cb.circuits[host]
+	if !ok {
+		c = new(circuit)
+		cb.circuits[host] = c
+	}
+
+	if trial {
+		c.trial = false
+	}
+
+	if !failed {
+		c.failures, c.openedAt = 0, time.Time{}
+		return
+	}
+
+	threshold := cb.Threshold
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+
+	c.failures++
+	if trial || c.failures >= threshold {
+		c.openedAt = time.Now()
+	}
+}
+
+func (cb *CircuitBreaker) Do(request *http.Request) (*http.Response, error) {
+	host := request.URL.Host
+	trial, err := cb.allow(host)
+	if err != nil {
+		return nil, err
+	}
+
+	client := cb.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	cb.record(host, trial, err != nil || response.StatusCode >= 500)
+	return response, err
+}
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a buffered resource held by a CachingResolver
+type cacheEntry struct {
+	resource Interface
+	err      error
+	expires  time.Time
+}
+
+// CachingResolver is a decorator that keeps resources in memory so that repeated resolutions of the same
+// value do not reload them.  Each value is loaded through the decorated Resolver and buffered the first time
+// it is resolved.  Later resolutions return the buffered copy until it expires, after which the next resolution
+// loads the resource again.  Failed loads are not cached, unless NegativeTTL is set.
+//
+// Since loading happens at resolution time, the handles returned by this resolver are always in-memory.
+// A CachingResolver is safe for concurrent use.
+type CachingResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// TTL is how long a buffered resource is reused.  If nonpositive, cached resources never expire.
+	TTL time.Duration
+
+	// NegativeTTL enables caching of missing resources.  When a load fails with an error satisfying
+	// errors.Is(err, ErrNotFound), that error is returned for this long without querying the decorated
+	// Resolver again.  If nonpositive, missing resources are not cached.
+	NegativeTTL time.Duration
+
+	lock    sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+func (cr *CachingResolver) cached(v string) (cacheEntry, bool) {
+	cr.lock.RLock()
+	defer cr.lock.RUnlock()
+
+	e, ok := cr.entries[v]
+	if !ok || (!e.expires.IsZero() && time.Now().After(e.expires)) {
+		return cacheEntry{}, false
+	}
+
+	return e, true
+}
+
+func (cr *CachingResolver) Resolve(v string) (Interface, error) {
+	if e, ok := cr.cached(v); ok {
+		return e.resource, e.err
+	}
+
+	return cr.Refresh(v)
+}
+
+// load resolves and buffers v through the decorated Resolver
+func (cr *CachingResolver) load(v string) (Interface, error) {
+	r, err := cr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Buffer(r)
+}
+
+// Refresh unconditionally loads v through the decorated Resolver, replacing any cached copy.  If the
+// load fails, the existing cached copy, if any, is left in place and the error is returned.  The exception
+// is a missing resource when NegativeTTL is set, which replaces the cached copy with the not found error.
+func (cr *CachingResolver) Refresh(v string) (Interface, error) {
+	r, err := cr.load(v)
+	var e cacheEntry
+	switch {
+	case err == nil:
+		e.resource = r
+		if cr.TTL > 0 {
+			e.expires = time.Now().Add(cr.TTL)
+		}
+
+	case cr.NegativeTTL > 0 && errors.Is(err, ErrNotFound):
+		e.err = err
+		e.expires = time.Now().Add(cr.NegativeTTL)
+
+	default:
+		return nil, err
+	}
+
+	cr.lock.Lock()
+	if cr.entries == nil {
+		cr.entries = make(map[string]cacheEntry)
+	}
+
+	cr.entries[v] = e
+	cr.lock.Unlock()
+
+	return e.resource, e.err
+}
+
+// Clear discards every cached resource, so that each value is reloaded the next time it is resolved
+func (cr *CachingResolver) Clear() {
+	cr.lock.Lock()
+	cr.entries = nil
+	cr.lock.Unlock()
+}
@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// CASScheme is the scheme conventionally mapped to a CASResolver
+const CASScheme = "cas"
+
+// casAlgorithms are the digest algorithms understood by CASResolver
+var casAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// CASStore is the minimal behavior of a content-addressable store
+type CASStore interface {
+	// Get returns the content stored under a digest of the form "algorithm:hex", e.g. "sha256:ab12...".
+	// If no such content exists, Get should return an error for which errors.Is(err, ErrNotFound) is true.
+	Get(digest string) ([]byte, error)
+}
+
+// CASResolver resolves values of the form "cas://sha256:ab12..." by fetching content from a content-addressable
+// store and verifying that it matches the digest in the value.  The sha256 and sha512 algorithms are supported.
+// Content that does not match results in a ChecksumError, so a returned handle always holds exactly the content
+// that the value identifies.  The returned handles are in-memory.
+type CASResolver struct {
+	// Store is the content-addressable store.  This field is required.
+	Store CASStore
+}
+
+func (r CASResolver) Resolve(v string) (Interface, error) {
+	_, digest := Split(v)
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return nil, newResolveError(v, fmt.Errorf("digest %q must have the form algorithm:hex", digest))
+	}
+
+	algorithm, expected := strings.ToLower(parts[0]), strings.ToLower(parts[1])
+	newHash, ok := casAlgorithms[algorithm]
+	if !ok {
+		return nil, newResolveError(v, fmt.Errorf("unsupported digest algorithm %q", parts[0]))
+	}
+
+	content, err := r.Store.Get(algorithm + ":" + expected)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	h := newHash()
+	h.Write(content)
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return nil, ChecksumError{Location: v, Expected: expected, Actual: actual}
+	}
+
+	return located{Bytes: Bytes(content), location: v}, nil
+}
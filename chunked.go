@@ -0,0 +1,163 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// ChecksumError indicates that the content of a resource did not match its expected digest
+type ChecksumError struct {
+	Location string
+	Expected string
+	Actual   string
+}
+
+func (e ChecksumError) Error() string {
+	return fmt.Sprintf("Checksum mismatch for %s: expected %s, got %s", e.Location, e.Expected, e.Actual)
+}
+
+// Chunk describes one piece of a chunked resource
+type Chunk struct {
+	// Value is the resource string of this chunk
+	Value string `json:"value"`
+
+	// SHA256 is the optional hex-encoded SHA-256 digest of this chunk's content
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Manifest describes a resource assembled from an ordered list of chunks
+type Manifest struct {
+	Chunks []Chunk `json:"chunks"`
+}
+
+type resolvedChunk struct {
+	resource Interface
+	sha256   string
+}
+
+// chunked is a resource whose content is the concatenation of its chunks
+type chunked struct {
+	location string
+	chunks   []resolvedChunk
+}
+
+func (c chunked) Location() string {
+	return c.location
+}
+
+func (c chunked) Open() (io.ReadCloser, error) {
+	return &chunkReader{chunks: c.chunks}, nil
+}
+
+func (c chunked) WriteTo(w io.Writer) (int64, error) {
+	rc, _ := c.Open()
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// chunkReader reads each chunk in turn, verifying digests as each chunk is exhausted
+type chunkReader struct {
+	chunks  []resolvedChunk
+	current io.ReadCloser
+	digest  hash.Hash
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if cr.current == nil {
+			if len(cr.chunks) == 0 {
+				return 0, io.EOF
+			}
+
+			rc, err := cr.chunks[0].resource.Open()
+			if err != nil {
+				return 0, err
+			}
+
+			cr.current, cr.digest = rc, sha256.New()
+		}
+
+		n, err := cr.current.Read(p)
+		cr.digest.Write(p[:n])
+		if err == io.EOF {
+			err = cr.next()
+			if n > 0 || err != nil {
+				return n, err
+			}
+
+			continue
+		}
+
+		return n, err
+	}
+}
+
+// next finishes the current chunk, verifying its digest
+func (cr *chunkReader) next() error {
+	cr.current.Close()
+	cr.current = nil
+
+	chunk := cr.chunks[0]
+	cr.chunks = cr.chunks[1:]
+	if len(chunk.sha256) == 0 {
+		return nil
+	}
+
+	actual := hex.EncodeToString(cr.digest.Sum(nil))
+	if !strings.EqualFold(actual, chunk.sha256) {
+		return ChecksumError{Location: chunk.resource.Location(), Expected: chunk.sha256, Actual: actual}
+	}
+
+	return nil
+}
+
+func (cr *chunkReader) Close() error {
+	if cr.current != nil {
+		return cr.current.Close()
+	}
+
+	return nil
+}
+
+// ChunkedResolver resolves resources assembled from chunks, such as "part.000", "part.001", and so on.
+// The value resolved is that of a JSON Manifest listing the chunks in order.  The returned handle streams
+// each chunk in turn, verifying the SHA-256 digest of each chunk that has one as it goes.
+type ChunkedResolver struct {
+	// Resolver is used to resolve both the manifest and each of its chunks.  This field is required.
+	Resolver Resolver
+}
+
+func (cr ChunkedResolver) Resolve(v string) (Interface, error) {
+	mr, err := cr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := mr.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+	var m Manifest
+	if err := json.NewDecoder(rc).Decode(&m); err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	c := chunked{location: mr.Location(), chunks: make([]resolvedChunk, len(m.Chunks))}
+	for i, chunk := range m.Chunks {
+		r, err := cr.Resolver.Resolve(chunk.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		c.chunks[i] = resolvedChunk{resource: r, sha256: chunk.SHA256}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,325 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PropertiesError describes a syntax error in a properties resource
+type PropertiesError struct {
+	Location string
+	Line     int
+	Reason   string
+}
+
+func (e PropertiesError) Error() string {
+	return fmt.Sprintf("Invalid properties in %s at line %d: %s", e.Location, e.Line, e.Reason)
+}
+
+// continues tests whether a properties line ends with an odd number of backslashes
+func continues(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+
+	return count%2 == 1
+}
+
+// unescapeProperty processes the escape sequences permitted in properties keys and values
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape %q", s[i-1:])
+			}
+
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape %q", s[i-1:i+5])
+			}
+
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// splitProperty separates a logical properties line into its raw key and value
+func splitProperty(line string) (key, value string) {
+	i := 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+	}
+
+	// a trailing backslash may have stepped past the end of the line
+	if i > len(line) {
+		i = len(line)
+	}
+
+	key, value = line[:i], strings.TrimLeft(line[i:], " \t\f")
+	if len(value) > 0 && (value[0] == '=' || value[0] == ':') {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+
+	return
+}
+
+// DecodeProperties opens a resource and parses it as a Java-style properties file.  Lines have
+// the form key=value, key:value, or key value.  Lines beginning with '#' or '!' are comments, a line
+// ending with an unescaped backslash continues onto the next line, and the usual escape sequences,
+// including \uXXXX, are processed.  The resource is always closed.  Syntax errors are reported as
+// a PropertiesError with the offending line number.
+func DecodeProperties(r Interface) (map[string]string, error) {
+	rc, err := r.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+
+	var (
+		properties = make(map[string]string)
+		br         = bufio.NewReader(rc)
+		lineNumber = 0
+		logical    strings.Builder
+		start      = 0
+	)
+
+	for done := false; !done; {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			done = true
+		} else if err != nil {
+			return nil, err
+		}
+
+		lineNumber++
+		line = strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if logical.Len() == 0 {
+			start = lineNumber
+			if len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!' {
+				continue
+			}
+		}
+
+		if continues(trimmed) {
+			trimmed = trimmed[:len(trimmed)-1]
+			if !done {
+				logical.WriteString(trimmed)
+				continue
+			}
+		}
+
+		logical.WriteString(trimmed)
+		rawKey, rawValue := splitProperty(logical.String())
+		logical.Reset()
+
+		key, err := unescapeProperty(rawKey)
+		if err == nil {
+			properties[key], err = unescapeProperty(rawValue)
+		}
+
+		if err != nil {
+			return nil, PropertiesError{Location: r.Location(), Line: start, Reason: err.Error()}
+		}
+	}
+
+	return properties, nil
+}
+
+// JSONLinesError describes a line of a JSON Lines resource that is not valid JSON
+type JSONLinesError struct {
+	Location string
+	Line     int
+	Err      error
+}
+
+func (e JSONLinesError) Error() string {
+	return fmt.Sprintf("Invalid JSON in %s at line %d: %s", e.Location, e.Line, e.Err)
+}
+
+func (e JSONLinesError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSONLines streams a JSON Lines (newline-delimited JSON) resource, invoking fn with each record.
+// Blank lines are skipped.  Iteration stops at the first invalid line, which is reported as a JSONLinesError,
+// or at the first error returned by fn, which is returned as is.  The resource is always closed, including
+// when iteration stops early.  Lines are not limited in length, and only one line is held in memory at a time.
+func DecodeJSONLines(r Interface, fn func(json.RawMessage) error) error {
+	rc, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	defer rc.Close()
+	br := bufio.NewReader(rc)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var record json.RawMessage
+			if decodeErr := json.Unmarshal(trimmed, &record); decodeErr != nil {
+				return JSONLinesError{Location: r.Location(), Line: lineNumber, Err: decodeErr}
+			}
+
+			if fnErr := fn(record); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// yamlMarker tests whether a line begins with the given YAML document marker, either "---" or "...".
+// Markers are only recognized at the start of a line and must be followed by whitespace or the end of the line.
+func yamlMarker(line, marker string) bool {
+	if !strings.HasPrefix(line, marker) {
+		return false
+	}
+
+	rest := line[len(marker):]
+	return len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n'
+}
+
+// yamlContent tests whether text is more than whitespace and comments
+func yamlContent(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	return len(trimmed) > 0 && trimmed[0] != '#'
+}
+
+// splitYAML splits YAML content into its documents.  By default, documents are split on marker lines.
+// Building with the yamlv3 tag replaces this with a splitter driven by the YAML scanner of gopkg.in/yaml.v3.
+var splitYAML = splitYAMLLines
+
+// splitYAMLLines splits YAML content on "---" and "..." marker lines
+func splitYAMLLines(content []byte) ([][]byte, error) {
+	var (
+		documents  [][]byte
+		current    bytes.Buffer
+		hasContent bool
+		hasMarker  bool
+		br         = bufio.NewReader(bytes.NewReader(content))
+	)
+
+	flush := func() {
+		if hasContent {
+			documents = append(documents, append([]byte(nil), current.Bytes()...))
+		}
+
+		current.Reset()
+		hasContent, hasMarker = false, false
+	}
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		switch {
+		case yamlMarker(line, "---"):
+			// a marker ends the previous document, but directives and comments before it belong to the next one
+			if hasContent || hasMarker {
+				flush()
+			}
+
+			current.WriteString(line)
+			hasContent, hasMarker = yamlContent(line[3:]), true
+
+		case yamlMarker(line, "..."):
+			current.WriteString(line)
+			flush()
+
+		default:
+			current.WriteString(line)
+			if !hasContent && yamlContent(line) && !strings.HasPrefix(line, "%") {
+				hasContent = true
+			}
+		}
+
+		if err == io.EOF {
+			flush()
+			return documents, nil
+		}
+	}
+}
+
+// SplitYAMLDocuments reads a multi-document YAML resource and splits it into one in-memory handle per document,
+// each reporting the location of r.  Documents are separated by "---" lines, and may be ended by "..." lines.
+// Each document's content is preserved verbatim, including its "---" line and any directives, such as %YAML,
+// that precede it.  Documents holding nothing but whitespace and comments are omitted.
+//
+// The YAML specification forbids document markers at the start of a line within any scalar, including quoted and
+// block scalars, so by default, documents are split on marker lines.  A "---" anywhere other than the start of a
+// line, such as within a quoted string or an indented block scalar, never splits a document.  Building with the
+// yamlv3 tag instead locates documents with the YAML scanner from gopkg.in/yaml.v3, which rejects malformed YAML
+// rather than guessing at its documents.
+func SplitYAMLDocuments(r Interface) ([]Interface, error) {
+	rc, err := r.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := FromReadCloser(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := splitYAML(content)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Interface, 0, len(split))
+	for _, document := range split {
+		documents = append(documents, located{Bytes: Bytes(document), location: r.Location()})
+	}
+
+	return documents, nil
+}
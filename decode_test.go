@@ -0,0 +1,63 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitYAMLDocuments(t *testing.T) {
+	testData := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			"Simple",
+			"a: 1\n---\nb: 2\n",
+			[]string{"a: 1\n", "---\nb: 2\n"},
+		},
+		{
+			"QuotedScalars",
+			"a: 'x --- y'\n---\nb: \"multi\n  --- line\"\nc: '\n  ---'\n",
+			[]string{"a: 'x --- y'\n", "---\nb: \"multi\n  --- line\"\nc: '\n  ---'\n"},
+		},
+		{
+			"BlockScalars",
+			"script: |\n  echo start\n  ---\n  echo end\nfolded: >\n  ---\n---\nnext: true\n",
+			[]string{"script: |\n  echo start\n  ---\n  echo end\nfolded: >\n  ---\n", "---\nnext: true\n"},
+		},
+		{
+			"EmptyDocuments",
+			"---\n# nothing here\n---\na: 1\n---\n",
+			[]string{"---\na: 1\n"},
+		},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			documents, err := SplitYAMLDocuments(String(record.content))
+			if err != nil {
+				t.Fatalf("SplitYAMLDocuments failed: %s", err)
+			}
+
+			var actual []string
+			for _, document := range documents {
+				if document.Location() != "string" {
+					t.Errorf("Expected each document to report the original location, got %s", document.Location())
+				}
+
+				// each document can be read independently, any number of times
+				first, second := readAll(t, document), readAll(t, document)
+				if first != second {
+					t.Errorf("Rereading a document produced different content: %q, %q", first, second)
+				}
+
+				actual = append(actual, first)
+			}
+
+			if !reflect.DeepEqual(actual, record.expected) {
+				t.Errorf("Expected documents %q, got %q", record.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+//go:build yamlv3
+// +build yamlv3
+
+package resource
+
+import (
+	"bytes"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	splitYAML = splitYAMLScanner
+}
+
+// emptyYAMLDocument tests whether a parsed document has no content, as opposed to an explicit null such as "~"
+func emptyYAMLDocument(document *yaml.Node) bool {
+	if len(document.Content) == 0 {
+		return true
+	}
+
+	n := document.Content[0]
+	return len(document.Content) == 1 && n.Kind == yaml.ScalarNode && n.Tag == "!!null" && len(n.Value) == 0 && n.Style == 0
+}
+
+// splitYAMLScanner splits YAML content at the lines where the YAML scanner reports that each document begins.
+// A document begins at its directives or its "---" marker, or at its first content if it has neither.  Comments
+// and "..." lines between two documents therefore stay with the first of them, and anything preceding the
+// first document stays with it.
+func splitYAMLScanner(content []byte) ([][]byte, error) {
+	var (
+		starts  []int
+		empty   []bool
+		decoder = yaml.NewDecoder(bytes.NewReader(content))
+	)
+
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		starts = append(starts, document.Line)
+		empty = append(empty, emptyYAMLDocument(&document))
+	}
+
+	// offsets[i] is the byte offset of line i+1
+	offsets := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+
+	offset := func(line int) int {
+		if line-1 < len(offsets) {
+			return offsets[line-1]
+		}
+
+		return len(content)
+	}
+
+	var documents [][]byte
+	for i := range starts {
+		begin, end := 0, len(content)
+		if i > 0 {
+			begin = offset(starts[i])
+		}
+
+		if i+1 < len(starts) {
+			end = offset(starts[i+1])
+		}
+
+		if !empty[i] {
+			documents = append(documents, append([]byte(nil), content[begin:end]...))
+		}
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,12 @@
+//go:build yamlv3
+// +build yamlv3
+
+package resource
+
+import "testing"
+
+func TestSplitYAMLDocumentsMalformed(t *testing.T) {
+	if _, err := SplitYAMLDocuments(String("a: [1, 2\n---\nb: 2\n")); err == nil {
+		t.Error("Expected malformed YAML to be rejected")
+	}
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// cacheKey produces the file name used to store the content of a resource value on disk
+func cacheKey(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+// CachedFile is the handle returned by a DiskCacheResolver.  It is a File that refers to
+// the on-disk copy of a resource, along with a flag indicating whether that copy is stale.
+type CachedFile struct {
+	File
+
+	// Stale is true if the underlying load failed and this handle refers to a copy
+	// left over from a previous successful load.  Stale is false if the copy was written
+	// as part of the resolution that produced this handle.
+	Stale bool
+}
+
+// DiskCacheResolver is a decorator that mirrors resources to a local directory so that they remain
+// available when the original source is not.  Each resolution loads the resource through the decorated
+// Resolver and writes a copy to Dir, keyed by a hash of the resource value.  If the load fails and a copy
+// from a previous load exists, that copy is returned instead.
+//
+// The handles returned by this resolver are always of type CachedFile.
+type DiskCacheResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Dir is the directory where cached copies are written.  This directory is created if necessary.
+	// This field is required.
+	Dir string
+}
+
+// store loads v through the decorated Resolver and writes its contents to path.  The contents are
+// written to a temporary file first, so that a failed load never clobbers an existing copy.
+func (dcr DiskCacheResolver) store(v, path string) error {
+	r, err := dcr.Resolver.Resolve(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dcr.Dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dcr.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.WriteTo(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
+
+func (dcr DiskCacheResolver) Resolve(v string) (Interface, error) {
+	path, err := filepath.Abs(filepath.Join(dcr.Dir, cacheKey(v)))
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	loadErr := dcr.store(v, path)
+	if loadErr == nil {
+		return CachedFile{File: File(path)}, nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, newResolveError(v, loadErr)
+	}
+
+	return CachedFile{File: File(path), Stale: true}, nil
+}
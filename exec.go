@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ExecError is returned when the command run by an ExecResolver fails
+type ExecError struct {
+	Location string
+	Command  string
+	Err      error
+
+	// Stderr is whatever the command wrote to its standard error, with surrounding whitespace removed
+	Stderr string
+}
+
+func (e ExecError) Error() string {
+	if len(e.Stderr) > 0 {
+		return fmt.Sprintf("Command %s failed for %s: %s: %s", e.Command, e.Location, e.Err, e.Stderr)
+	}
+
+	return fmt.Sprintf("Command %s failed for %s: %s", e.Command, e.Location, e.Err)
+}
+
+func (e ExecError) Unwrap() error {
+	return e.Err
+}
+
+// ExecResolver is a decorator that pipes resources through an external command, such as a decryption tool
+// like sops or age.  Each time a returned handle is opened, the command is started, the decorated resource's
+// content is streamed to its standard input, and its standard output becomes the handle's content.  The output
+// is buffered in memory, so that a failed command never yields partial content.  A command that exits with a
+// nonzero status results in an ExecError that includes its standard error.
+type ExecResolver struct {
+	// Resolver is the decorated Resolver that produces each command's input.  This field is required.
+	Resolver Resolver
+
+	// Command is the program to run.  It is located with exec.LookPath.  This field is required.
+	Command string
+
+	// Args are the optional arguments passed to Command
+	Args []string
+
+	// Env is the optional environment of the command.  If not supplied, the current process's environment is used.
+	Env []string
+}
+
+func (er ExecResolver) run(location string, input io.ReadCloser) (io.ReadCloser, error) {
+	var (
+		cmd            = exec.Command(er.Command, er.Args...)
+		stdout, stderr bytes.Buffer
+	)
+
+	cmd.Env = er.Env
+	cmd.Stdin = input
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, ExecError{
+			Location: location,
+			Command:  er.Command,
+			Err:      err,
+			Stderr:   strings.TrimSpace(stderr.String()),
+		}
+	}
+
+	return readCloser{Reader: bytes.NewReader(stdout.Bytes()), Closer: input}, nil
+}
+
+func (er ExecResolver) Resolve(v string) (Interface, error) {
+	r, err := er.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformed{
+		Interface: r,
+		transform: func(rc io.ReadCloser) (io.ReadCloser, error) {
+			return er.run(r.Location(), rc)
+		},
+	}, nil
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// FDScheme is the scheme conventionally mapped to an FDResolver
+const FDScheme = "fd"
+
+// ErrFDAlreadyOpened is returned when an inherited file descriptor is opened more than once
+var ErrFDAlreadyOpened = errors.New("File descriptor has already been opened")
+
+// FD represents a resource backed by an inherited file descriptor, such as those passed by systemd
+// or a container runtime.  Since a descriptor is a one-shot stream, it can only be opened once.
+// Subsequent calls to Open or WriteTo return ErrFDAlreadyOpened.
+type FD struct {
+	fd uintptr
+
+	lock   sync.Mutex
+	opened bool
+}
+
+// NewFD creates an FD resource for the given file descriptor
+func NewFD(fd uintptr) *FD {
+	return &FD{fd: fd}
+}
+
+func (f *FD) Location() string {
+	return FDScheme + SchemeSeparator + strconv.FormatUint(uint64(f.fd), 10)
+}
+
+// Rereadable always returns false, since a descriptor can only be opened once
+func (f *FD) Rereadable() bool {
+	return false
+}
+
+func (f *FD) Open() (io.ReadCloser, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.opened {
+		return nil, ErrFDAlreadyOpened
+	}
+
+	f.opened = true
+	return os.NewFile(f.fd, f.Location()), nil
+}
+
+func (f *FD) WriteTo(w io.Writer) (int64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// FDResolver resolves values such as "fd://3" as inherited file descriptors.  Resolving the same
+// descriptor more than once returns the same handle, so that the descriptor is only ever opened once.
+type FDResolver struct {
+	lock    sync.Mutex
+	handles map[uintptr]*FD
+}
+
+func (r *FDResolver) Resolve(v string) (Interface, error) {
+	_, value := Split(v)
+	n, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	fd := uintptr(n)
+	if h, ok := r.handles[fd]; ok {
+		return h, nil
+	}
+
+	if r.handles == nil {
+		r.handles = make(map[uintptr]*FD)
+	}
+
+	h := NewFD(fd)
+	r.handles[fd] = h
+	return h, nil
+}
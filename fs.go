@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// fsFileInfo is the fs.FileInfo synthesized for a resource
+type fsFileInfo struct {
+	name string
+	size int64
+}
+
+func (fi fsFileInfo) Name() string {
+	return fi.name
+}
+
+func (fi fsFileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi fsFileInfo) Mode() fs.FileMode {
+	return 0444
+}
+
+func (fi fsFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (fi fsFileInfo) IsDir() bool {
+	return false
+}
+
+func (fi fsFileInfo) Sys() interface{} {
+	return nil
+}
+
+// fsFile is a buffered resource presented as an fs.File
+type fsFile struct {
+	*bytes.Reader
+	info fsFileInfo
+}
+
+func (f fsFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f fsFile) Close() error {
+	return nil
+}
+
+type resolverFS struct {
+	r Resolver
+}
+
+func (rfs resolverFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	h, err := rfs.r.Resolve(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	var content bytes.Buffer
+	if _, err := h.WriteTo(&content); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return fsFile{
+		Reader: bytes.NewReader(content.Bytes()),
+		info:   fsFileInfo{name: path.Base(name), size: int64(content.Len())},
+	}, nil
+}
+
+// FS adapts a Resolver to the fs.FS interface, so that resources can be used with anything that consumes
+// file systems, such as template.ParseFS or http.FS.  Each name passed to Open is resolved with r, and the
+// resource's content is buffered in memory.  Names must satisfy fs.ValidPath, so they cannot carry a scheme.
+// Directories are not supported, so operations such as fs.ReadDir and fs.Glob fail.
+//
+// Errors from resolving or loading a resource are reported as *fs.PathError, and missing resources
+// satisfy errors.Is(err, fs.ErrNotExist).
+func FS(r Resolver) fs.FS {
+	return resolverFS{r: r}
+}
@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitHubScheme is the scheme conventionally mapped to a GitHubResolver
+const GitHubScheme = "github"
+
+// DefaultGitHubBaseURL is the location from which raw GitHub content is served
+const DefaultGitHubBaseURL = "https://raw.githubusercontent.com"
+
+// ErrInvalidGitHubValue indicates that a value did not have the form owner/repo/path[@ref]
+var ErrInvalidGitHubValue = errors.New("GitHub resources must have the form owner/repo/path[@ref]")
+
+// GitHubResolver resolves files stored in GitHub repositories.  Values have the form
+// "github://owner/repo/path/to/file@ref", where the ref is an optional branch, tag, or commit.
+// If no ref is supplied, the repository's default branch is used.  Each value is translated into
+// a raw content URL and resolved as an HTTP resource.
+type GitHubResolver struct {
+	// BaseURL is the location of raw repository content.  If not supplied, DefaultGitHubBaseURL is used.
+	BaseURL string
+
+	// Token is the optional access token used to authenticate with GitHub, e.g. for private repositories
+	Token string
+
+	// Client is the HTTP client used to obtain resources.  If not supplied, http.DefaultClient is used.
+	Client HTTPClient
+}
+
+// rawURL translates a GitHub resource value into a raw content URL
+func (r GitHubResolver) rawURL(v string) (string, error) {
+	_, value := Split(v)
+
+	ref := "HEAD"
+	if i := strings.LastIndexByte(value, '@'); i >= 0 {
+		value, ref = value[:i], value[i+1:]
+	}
+
+	parts := strings.SplitN(strings.Trim(value, "/"), "/", 3)
+	if len(parts) < 3 || len(parts[0]) == 0 || len(parts[1]) == 0 || len(parts[2]) == 0 || len(ref) == 0 {
+		return "", ErrInvalidGitHubValue
+	}
+
+	base := r.BaseURL
+	if len(base) == 0 {
+		base = DefaultGitHubBaseURL
+	}
+
+	return strings.TrimRight(base, "/") + "/" +
+		url.PathEscape(parts[0]) + "/" +
+		url.PathEscape(parts[1]) + "/" +
+		url.PathEscape(ref) + "/" +
+		parts[2], nil
+}
+
+func (r GitHubResolver) Resolve(v string) (Interface, error) {
+	u, err := r.rawURL(v)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	client := r.Client
+	if len(r.Token) > 0 {
+		if client == nil {
+			client = http.DefaultClient
+		}
+
+		client = WithHeader("Authorization", "token "+r.Token, client)
+	}
+
+	return HTTPResolver{Client: client}.Resolve(u)
+}
@@ -0,0 +1,479 @@
+package resource
+
+import (
+	"context"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Unwrapper is implemented by resource handles that decorate another handle.  Decorators should implement
+// this interface so that capabilities attached to the handles they wrap, such as metadata, remain reachable.
+type Unwrapper interface {
+	// Unwrap returns the decorated handle
+	Unwrap() Interface
+}
+
+// ReadTimeoutError is returned when a read from a resource blocks for longer than the allowed duration
+type ReadTimeoutError struct {
+	Location string
+	Duration time.Duration
+}
+
+func (e ReadTimeoutError) Error() string {
+	return fmt.Sprintf("Read from %s timed out after %s", e.Location, e.Duration)
+}
+
+// Timeout always returns true.  This method allows this error to be treated like a net.Error.
+func (e ReadTimeoutError) Timeout() bool {
+	return true
+}
+
+// readDeadliner is implemented by readers, such as *os.File and net.Conn, that natively support read deadlines
+type readDeadliner interface {
+	SetReadDeadline(time.Time) error
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+// timeoutReader enforces a timeout on each individual Read
+type timeoutReader struct {
+	rc       io.ReadCloser
+	location string
+	d        time.Duration
+	err      error
+
+	// buffer is reused across reads performed in a separate goroutine.  This is safe because
+	// a new read only starts once the previous one has completed.
+	buffer []byte
+
+	// closed is set when rc has already been closed because a read timed out
+	closed bool
+}
+
+func (tr *timeoutReader) Read(p []byte) (int, error) {
+	if tr.err != nil {
+		return 0, tr.err
+	}
+
+	if rd, ok := tr.rc.(readDeadliner); ok {
+		if err := rd.SetReadDeadline(time.Now().Add(tr.d)); err == nil {
+			n, err := tr.rc.Read(p)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				tr.err = ReadTimeoutError{Location: tr.location, Duration: tr.d}
+				err = tr.err
+			}
+
+			return n, err
+		}
+	}
+
+	// the read happens into a separate buffer, since a timed out read
+	// may still complete after this method returns
+	if cap(tr.buffer) < len(p) {
+		tr.buffer = make([]byte, len(p))
+	}
+
+	var (
+		buffer = tr.buffer[:len(p)]
+		result = make(chan readResult, 1)
+		timer  = time.NewTimer(tr.d)
+	)
+
+	defer timer.Stop()
+	go func() {
+		n, err := tr.rc.Read(buffer)
+		result <- readResult{n, err}
+	}()
+
+	select {
+	case r := <-result:
+		copy(p, buffer[:r.n])
+		return r.n, r.err
+
+	case <-timer.C:
+		// closing the underlying reader unblocks the pending read for most sources.  The buffer
+		// is abandoned to that read, and no further reads will happen.
+		tr.err = ReadTimeoutError{Location: tr.location, Duration: tr.d}
+		tr.buffer = nil
+		tr.closed = true
+		tr.rc.Close()
+		return 0, tr.err
+	}
+}
+
+// Close closes the underlying reader, unless a timed out read has already closed it
+func (tr *timeoutReader) Close() error {
+	if tr.closed {
+		return nil
+	}
+
+	return tr.rc.Close()
+}
+
+type readTimeout struct {
+	Interface
+	d time.Duration
+}
+
+func (rt readTimeout) Unwrap() Interface {
+	return rt.Interface
+}
+
+func (rt readTimeout) Open() (io.ReadCloser, error) {
+	rc, err := rt.Interface.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &timeoutReader{rc: rc, location: rt.Location(), d: rt.d}, nil
+}
+
+func (rt readTimeout) WriteTo(w io.Writer) (int64, error) {
+	rc, err := rt.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// WithReadTimeout decorates a resource handle so that each Read from the io.ReadCloser returned
+// by Open fails with a ReadTimeoutError if no data arrives within the given duration.  Readers that
+// support read deadlines, such as files and network connections, use those deadlines directly.  Other
+// readers perform each Read in a separate goroutine.  Once a read has timed out, the underlying reader
+// is closed and all subsequent reads fail.
+func WithReadTimeout(d time.Duration, r Interface) Interface {
+	return readTimeout{Interface: r, d: d}
+}
+
+// Retrying is a resource handle that retries failed opens of another handle.  Only opening is retried:
+// once any data has been delivered, errors are returned as is, so a partially consumed stream is never reread.
+type Retrying struct {
+	Interface
+
+	// Attempts is the maximum number of times Open is attempted.  Values less than 1 are treated as 1.
+	Attempts int
+
+	// Delay is the time to wait between attempts
+	Delay time.Duration
+
+	// Retryable is the optional predicate that decides whether an error from Open is worth retrying.
+	// If not supplied, all errors are retried.
+	Retryable func(error) bool
+}
+
+// Unwrap returns the handle whose opens are retried
+func (r Retrying) Unwrap() Interface {
+	return r.Interface
+}
+
+func (r Retrying) Open() (io.ReadCloser, error) {
+	for attempt := 1; ; attempt++ {
+		rc, err := r.Interface.Open()
+		if err == nil {
+			return rc, nil
+		}
+
+		if attempt >= r.Attempts || (r.Retryable != nil && !r.Retryable(err)) {
+			return nil, err
+		}
+
+		time.Sleep(r.Delay)
+	}
+}
+
+func (r Retrying) WriteTo(w io.Writer) (int64, error) {
+	rc, err := r.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// WithOpenRetry decorates a resource handle so that Open, and thus WriteTo, is attempted up to the given
+// number of times with a delay between attempts.  To retry only certain errors, set Retryable on the result.
+func WithOpenRetry(r Interface, attempts int, delay time.Duration) Retrying {
+	return Retrying{Interface: r, Attempts: attempts, Delay: delay}
+}
+
+// throttledReader limits the rate at which bytes are read using a token bucket that holds
+// at most one second's worth of bytes
+type throttledReader struct {
+	rc     io.ReadCloser
+	rate   int64
+	tokens int64
+	last   time.Time
+	once   sync.Once
+	closed chan struct{}
+
+	// ctx is the optional context passed to OpenContext
+	ctx context.Context
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	// a nil channel never fires, so reads opened without a context only wait on the timer and Close
+	var done <-chan struct{}
+	if tr.ctx != nil {
+		if err := tr.ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		done = tr.ctx.Done()
+	}
+
+	if int64(len(p)) > tr.rate {
+		p = p[:tr.rate]
+	}
+
+	n, err := tr.rc.Read(p)
+
+	now := time.Now()
+	if tr.last.IsZero() {
+		tr.tokens = tr.rate
+	} else {
+		tr.tokens += int64(now.Sub(tr.last).Seconds() * float64(tr.rate))
+		if tr.tokens > tr.rate {
+			tr.tokens = tr.rate
+		}
+	}
+
+	tr.last = now
+	tr.tokens -= int64(n)
+	if tr.tokens < 0 {
+		// wait until the debt is repaid, unless the reader is closed or its context is done in the meantime
+		timer := time.NewTimer(time.Duration(float64(-tr.tokens) / float64(tr.rate) * float64(time.Second)))
+		select {
+		case <-timer.C:
+		case <-tr.closed:
+			timer.Stop()
+		case <-done:
+			timer.Stop()
+			if err == nil {
+				err = tr.ctx.Err()
+			}
+		}
+	}
+
+	return n, err
+}
+
+func (tr *throttledReader) Close() error {
+	tr.once.Do(func() { close(tr.closed) })
+	return tr.rc.Close()
+}
+
+type throttled struct {
+	Interface
+	rate int64
+}
+
+func (t throttled) Unwrap() Interface {
+	return t.Interface
+}
+
+func (t throttled) Open() (io.ReadCloser, error) {
+	rc, err := t.Interface.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttledReader{rc: rc, rate: t.rate, closed: make(chan struct{})}, nil
+}
+
+// OpenContext is like Open, except that reads waiting on the limit are interrupted once ctx is done,
+// returning ctx.Err().  The decorated handle is opened with OpenContext as well.
+func (t throttled) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	rc, err := OpenContext(ctx, t.Interface)
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttledReader{rc: rc, rate: t.rate, closed: make(chan struct{}), ctx: ctx}, nil
+}
+
+func (t throttled) WriteTo(w io.Writer) (int64, error) {
+	rc, err := t.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// WithBandwidthLimit decorates a resource handle so that its content is read no faster than the given
+// number of bytes per second, for both Open and WriteTo.  Bursts of up to one second's worth of bytes are
+// permitted.  Closing the io.ReadCloser returned by Open interrupts any read that is waiting on the limit, so
+// a consumer that goes away is never left blocked.  The returned handle implements ContextOpener, so a read
+// opened with OpenContext is also interrupted once its context is done.  A non-positive limit disables throttling.
+func WithBandwidthLimit(bytesPerSec int64, r Interface) Interface {
+	if bytesPerSec <= 0 {
+		return r
+	}
+
+	return throttled{Interface: r, rate: bytesPerSec}
+}
+
+// UnavailableHashError is returned when a resource is read through WithDigest with a hash algorithm that is
+// not linked into the binary
+type UnavailableHashError struct {
+	Location string
+	Hash     crypto.Hash
+}
+
+func (e UnavailableHashError) Error() string {
+	return fmt.Sprintf("Unable to digest %s: %s is not linked into the binary", e.Location, e.Hash)
+}
+
+// Digest holds the digest of a resource's content, computed as a side effect of reading it.
+// A Digest is safe for concurrent use.
+type Digest struct {
+	lock sync.Mutex
+	sum  []byte
+}
+
+func (d *Digest) set(sum []byte) {
+	d.lock.Lock()
+	d.sum = sum
+	d.lock.Unlock()
+}
+
+// Sum returns the digest of the most recent complete read of the resource, or nil if the resource
+// has not yet been read through to the end
+func (d *Digest) Sum() []byte {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]byte(nil), d.sum...)
+}
+
+// Hex returns Sum as a lowercase hexadecimal string, or the empty string if no digest is available yet
+func (d *Digest) Hex() string {
+	return hex.EncodeToString(d.Sum())
+}
+
+// digestReader hashes content as it is read, recording the digest once the end of the stream is reached
+type digestReader struct {
+	rc     io.ReadCloser
+	h      hash.Hash
+	digest *Digest
+}
+
+func (dr *digestReader) Read(p []byte) (int, error) {
+	n, err := dr.rc.Read(p)
+	dr.h.Write(p[:n])
+	if err == io.EOF {
+		dr.digest.set(dr.h.Sum(nil))
+	}
+
+	return n, err
+}
+
+func (dr *digestReader) Close() error {
+	return dr.rc.Close()
+}
+
+type digesting struct {
+	Interface
+	algo   crypto.Hash
+	digest *Digest
+}
+
+func (d digesting) Unwrap() Interface {
+	return d.Interface
+}
+
+func (d digesting) Open() (io.ReadCloser, error) {
+	if !d.algo.Available() {
+		return nil, UnavailableHashError{Location: d.Location(), Hash: d.algo}
+	}
+
+	rc, err := d.Interface.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &digestReader{rc: rc, h: d.algo.New(), digest: d.digest}, nil
+}
+
+func (d digesting) WriteTo(w io.Writer) (int64, error) {
+	rc, err := d.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// WithDigest decorates a resource handle so that the digest of its content is computed as it is read
+// through Open or WriteTo.  The returned Digest is populated only once the content has been read to the end,
+// so a partially consumed stream never produces a digest.  The hash algorithm must be linked into the binary,
+// e.g. by importing crypto/sha256, or Open and WriteTo fail with an UnavailableHashError.  Unlike a checksum,
+// the digest is never compared against anything.
+func WithDigest(algo crypto.Hash, r Interface) (Interface, *Digest) {
+	d := new(Digest)
+	return digesting{Interface: r, algo: algo, digest: d}, d
+}
+
+type withMetadata struct {
+	Interface
+	md map[string]interface{}
+}
+
+func (wm withMetadata) Unwrap() Interface {
+	return wm.Interface
+}
+
+// WithMetadata decorates a resource handle with arbitrary metadata, such as the resolver that produced it or
+// whether it came from a cache.  The content of the handle is unaffected.  The metadata can be read back with
+// Metadata, even after the returned handle has been further decorated.  The given map is copied.
+func WithMetadata(r Interface, md map[string]interface{}) Interface {
+	clone := make(map[string]interface{}, len(md))
+	for k, v := range md {
+		clone[k] = v
+	}
+
+	return withMetadata{Interface: r, md: clone}
+}
+
+// Metadata returns the metadata attached to a resource handle by WithMetadata, following Unwrapper to reach
+// handles wrapped by other decorators.  When metadata was attached more than once, all of it is merged, and
+// the outermost value for each key wins.  If there is no metadata, this function returns nil.
+func Metadata(r Interface) map[string]interface{} {
+	var md map[string]interface{}
+	for r != nil {
+		if wm, ok := r.(withMetadata); ok {
+			if md == nil {
+				md = make(map[string]interface{}, len(wm.md))
+			}
+
+			for k, v := range wm.md {
+				if _, exists := md[k]; !exists {
+					md[k] = v
+				}
+			}
+		}
+
+		u, ok := r.(Unwrapper)
+		if !ok {
+			break
+		}
+
+		r = u.Unwrap()
+	}
+
+	return md
+}
@@ -0,0 +1,179 @@
+package resource
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// stallingReader blocks each Read until it is closed, and counts the calls to Close
+type stallingReader struct {
+	once   sync.Once
+	done   chan struct{}
+	closes int
+}
+
+func (sr *stallingReader) Read([]byte) (int, error) {
+	<-sr.done
+	return 0, os.ErrClosed
+}
+
+func (sr *stallingReader) Close() error {
+	sr.closes++
+	sr.once.Do(func() { close(sr.done) })
+	return nil
+}
+
+func TestWithReadTimeoutPassesDataThrough(t *testing.T) {
+	r := WithReadTimeout(time.Second, Func{
+		Source: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(iotest.OneByteReader(strings.NewReader("hello world"))), nil
+		},
+	})
+
+	rc, err := r.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %s", err)
+	}
+
+	if string(data) != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", data)
+	}
+}
+
+func TestWithReadTimeoutDeadline(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unable to create pipe: %s", err)
+	}
+
+	defer pw.Close()
+	r := WithReadTimeout(10*time.Millisecond, Func{
+		Source: func() (io.ReadCloser, error) { return pr, nil },
+	})
+
+	rc, err := r.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	var rte ReadTimeoutError
+	if _, err := rc.Read(make([]byte, 8)); !errors.As(err, &rte) {
+		t.Fatalf("Expected a ReadTimeoutError, got %v", err)
+	}
+
+	if _, err := rc.Read(make([]byte, 8)); !errors.As(err, &rte) {
+		t.Errorf("Expected subsequent reads to fail with a ReadTimeoutError, got %v", err)
+	}
+
+	if err := rc.Close(); err != nil {
+		t.Errorf("Close failed: %s", err)
+	}
+}
+
+func TestWithReadTimeoutGoroutine(t *testing.T) {
+	sr := &stallingReader{done: make(chan struct{})}
+	r := WithReadTimeout(10*time.Millisecond, Func{
+		Source: func() (io.ReadCloser, error) { return sr, nil },
+	})
+
+	rc, err := r.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	var rte ReadTimeoutError
+	if _, err := rc.Read(make([]byte, 8)); !errors.As(err, &rte) {
+		t.Fatalf("Expected a ReadTimeoutError, got %v", err)
+	}
+
+	if err := rc.Close(); err != nil {
+		t.Errorf("Close after a timeout should succeed, got %s", err)
+	}
+
+	if sr.closes != 1 {
+		t.Errorf("Expected the underlying reader to be closed exactly once, got %d", sr.closes)
+	}
+}
+
+func TestWithBandwidthLimitContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := WithBandwidthLimit(10, String(strings.Repeat("x", 100)))
+	rc, err := OpenContext(ctx, r)
+	if err != nil {
+		t.Fatalf("OpenContext failed: %s", err)
+	}
+
+	defer rc.Close()
+
+	// the first read consumes the initial burst, so the second must wait about a second for more tokens
+	buffer := make([]byte, 10)
+	if _, err := rc.Read(buffer); err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := rc.Read(buffer); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Cancelling the context did not interrupt the throttled read, which took %s", elapsed)
+	}
+
+	if _, err := rc.Read(buffer); err != context.Canceled {
+		t.Errorf("Expected reads after cancellation to fail with context.Canceled, got %v", err)
+	}
+}
+
+func TestWithDigest(t *testing.T) {
+	r, d := WithDigest(crypto.SHA256, String("hello world"))
+	if d.Sum() != nil {
+		t.Errorf("Expected no digest before the content is read, got %x", d.Sum())
+	}
+
+	if actual := readAll(t, r); actual != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", actual)
+	}
+
+	if expected := fmt.Sprintf("%x", sha256.Sum256([]byte("hello world"))); d.Hex() != expected {
+		t.Errorf("Expected digest %s, got %s", expected, d.Hex())
+	}
+}
+
+func TestWithDigestUnavailableHash(t *testing.T) {
+	// MD4 lives outside the standard library, so it is never linked into the test binary
+	r, d := WithDigest(crypto.MD4, String("hello world"))
+
+	var uhe UnavailableHashError
+	if _, err := r.Open(); !errors.As(err, &uhe) || uhe.Hash != crypto.MD4 {
+		t.Errorf("Expected an UnavailableHashError from Open, got %v", err)
+	}
+
+	if _, err := r.WriteTo(ioutil.Discard); !errors.As(err, &uhe) {
+		t.Errorf("Expected an UnavailableHashError from WriteTo, got %v", err)
+	}
+
+	if d.Sum() != nil {
+		t.Errorf("Expected no digest, got %x", d.Sum())
+	}
+}
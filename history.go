@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of entries retained by a HistoryResolver when no Size is configured
+const DefaultHistorySize = 100
+
+// HistoryEntry records a single resolution performed through a HistoryResolver
+type HistoryEntry struct {
+	// Value is the resource value that was resolved
+	Value string
+
+	// Location is the location of the resulting resource.  This is empty if resolution failed.
+	Location string
+
+	// Time is when the resolution completed
+	Time time.Time
+
+	// Err is the error returned by the resolution, if any
+	Err error
+}
+
+// HistoryResolver is a decorator that remembers the most recent resolutions performed through it,
+// successful or not.  This is intended for introspection, such as a debug endpoint that reports which
+// resources an application actually loaded.  A HistoryResolver is safe for concurrent use.
+type HistoryResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Size is the maximum number of entries retained.  If nonpositive, DefaultHistorySize is used.
+	// Once full, each new entry replaces the oldest one.  Size may be changed between resolutions, in which
+	// case the oldest entries are discarded as necessary.
+	Size int
+
+	lock     sync.Mutex
+	entries  []HistoryEntry
+	next     int
+	capacity int
+}
+
+// ordered returns the retained entries, oldest first
+func (hr *HistoryResolver) ordered() []HistoryEntry {
+	ordered := make([]HistoryEntry, 0, len(hr.entries))
+	ordered = append(ordered, hr.entries[hr.next:]...)
+	return append(ordered, hr.entries[:hr.next]...)
+}
+
+func (hr *HistoryResolver) record(e HistoryEntry) {
+	hr.lock.Lock()
+	defer hr.lock.Unlock()
+
+	size := hr.Size
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	// when the size changes, compact the ring into order and discard the oldest entries that no longer fit,
+	// so that appending or overwriting from here on preserves the oldest-first order
+	if size != hr.capacity {
+		entries := hr.ordered()
+		if len(entries) > size {
+			entries = entries[len(entries)-size:]
+		}
+
+		hr.entries, hr.next, hr.capacity = entries, 0, size
+	}
+
+	if len(hr.entries) < size {
+		hr.entries = append(hr.entries, e)
+		return
+	}
+
+	hr.entries[hr.next] = e
+	hr.next = (hr.next + 1) % len(hr.entries)
+}
+
+func (hr *HistoryResolver) Resolve(v string) (Interface, error) {
+	r, err := hr.Resolver.Resolve(v)
+	e := HistoryEntry{Value: v, Time: time.Now(), Err: err}
+	if err == nil {
+		e.Location = r.Location()
+	}
+
+	hr.record(e)
+	return r, err
+}
+
+// History returns a copy of the retained entries, oldest first
+func (hr *HistoryResolver) History() []HistoryEntry {
+	hr.lock.Lock()
+	defer hr.lock.Unlock()
+	return hr.ordered()
+}
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// historyValues resolves each value through hr and returns the values reported by History
+func historyValues(hr *HistoryResolver, values ...string) []string {
+	for _, v := range values {
+		hr.Resolve(v)
+	}
+
+	var actual []string
+	for _, e := range hr.History() {
+		actual = append(actual, e.Value)
+	}
+
+	return actual
+}
+
+func TestHistoryResolverOrder(t *testing.T) {
+	hr := &HistoryResolver{
+		Resolver: ResolverFunc(func(v string) (Interface, error) {
+			if v == "bad" {
+				return nil, errors.New("expected")
+			}
+
+			return String(v), nil
+		}),
+		Size: 3,
+	}
+
+	if actual := historyValues(hr, "a", "b"); !reflect.DeepEqual(actual, []string{"a", "b"}) {
+		t.Errorf("Unexpected history before the ring is full: %q", actual)
+	}
+
+	if actual := historyValues(hr, "bad", "c", "d"); !reflect.DeepEqual(actual, []string{"bad", "c", "d"}) {
+		t.Errorf("Unexpected history after the ring wrapped: %q", actual)
+	}
+
+	hr.Size = 5
+	if actual := historyValues(hr, "e", "f", "g"); !reflect.DeepEqual(actual, []string{"c", "d", "e", "f", "g"}) {
+		t.Errorf("Unexpected history after growing the ring: %q", actual)
+	}
+
+	hr.Size = 2
+	if actual := historyValues(hr, "h"); !reflect.DeepEqual(actual, []string{"g", "h"}) {
+		t.Errorf("Unexpected history after shrinking the ring: %q", actual)
+	}
+
+	history := hr.History()
+	if history[0].Location != "string" || history[0].Err != nil {
+		t.Errorf("Unexpected entry: %#v", history[0])
+	}
+}
+
+func TestHistoryResolverConcurrency(t *testing.T) {
+	const (
+		goroutines  = 8
+		resolutions = 100
+		size        = 50
+	)
+
+	hr := &HistoryResolver{
+		Resolver: ResolverFunc(func(v string) (Interface, error) { return String(v), nil }),
+		Size:     size,
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < resolutions; i++ {
+				hr.Resolve(strconv.Itoa(g) + ":" + strconv.Itoa(i))
+				hr.History()
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	history := hr.History()
+	if len(history) != size {
+		t.Fatalf("Expected %d entries, got %d", size, len(history))
+	}
+
+	// within each goroutine, resolutions happen in order, so its entries must appear oldest first
+	last := make(map[int]int)
+	for _, e := range history {
+		var g, i int
+		if _, err := fmt.Sscanf(e.Value, "%d:%d", &g, &i); err != nil {
+			t.Fatalf("Unexpected value %q: %s", e.Value, err)
+		}
+
+		if previous, ok := last[g]; ok && previous >= i {
+			t.Errorf("Entries for goroutine %d are out of order: %d after %d", g, i, previous)
+		}
+
+		last[g] = i
+	}
+}
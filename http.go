@@ -2,11 +2,15 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httputil"
 	"net/textproto"
+	"sync"
 	"time"
 )
 
@@ -22,6 +26,84 @@ func (f HTTPClientFunc) Do(request *http.Request) (*http.Response, error) {
 	return f(request)
 }
 
+// FromRoundTripper adapts an http.RoundTripper, such as a tracing or mocking transport, into an HTTPClient.
+// Unlike an *http.Client, the returned HTTPClient passes each request directly to the round tripper, so
+// redirects are not followed, no cookie jar is consulted, and no client-level timeout applies.  To get that
+// behavior, use &http.Client{Transport: rt} instead.
+func FromRoundTripper(rt http.RoundTripper) HTTPClient {
+	return HTTPClientFunc(rt.RoundTrip)
+}
+
+// HTTPClientOption configures an *http.Client, and its *http.Transport, created by NewHTTPClient
+type HTTPClientOption func(*http.Client, *http.Transport)
+
+// NewHTTPClient builds an *http.Client whose transport is a clone of http.DefaultTransport, customized
+// by the given options.  Options are applied in order.
+func NewHTTPClient(options ...HTTPClientOption) *http.Client {
+	var (
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		client    = &http.Client{Transport: transport}
+	)
+
+	for _, o := range options {
+		o(client, transport)
+	}
+
+	return client
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle connections kept per host.  The net/http
+// default is only 2, which throttles bulk resolution of resources from the same host.
+func WithMaxIdleConnsPerHost(n int) HTTPClientOption {
+	return func(_ *http.Client, t *http.Transport) {
+		t.MaxIdleConnsPerHost = n
+	}
+}
+
+// WithForceHTTP2 controls whether HTTP/2 is attempted even though the transport has been customized
+func WithForceHTTP2(force bool) HTTPClientOption {
+	return func(_ *http.Client, t *http.Transport) {
+		t.ForceAttemptHTTP2 = force
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection remains in the pool before being closed
+func WithIdleConnTimeout(d time.Duration) HTTPClientOption {
+	return func(_ *http.Client, t *http.Transport) {
+		t.IdleConnTimeout = d
+	}
+}
+
+// WithDisableKeepAlives controls whether connections are closed after each request.  Keep-alives are
+// enabled by default, and HTTP resources fully consume response bodies on Close so that the shared
+// client's connections are reused across resolutions.
+func WithDisableKeepAlives(disable bool) HTTPClientOption {
+	return func(_ *http.Client, t *http.Transport) {
+		t.DisableKeepAlives = disable
+	}
+}
+
+// WithConnectTimeout limits the time spent establishing each connection, including DNS resolution.
+// Unlike WithTimeout, this does not limit the time spent reading a response body.
+func WithConnectTimeout(d time.Duration) HTTPClientOption {
+	return func(_ *http.Client, t *http.Transport) {
+		dialer := &net.Dialer{
+			Timeout:   d,
+			KeepAlive: 30 * time.Second,
+		}
+
+		t.DialContext = dialer.DialContext
+	}
+}
+
+// WithResponseHeaderTimeout limits the time spent waiting for a server's response headers once a request
+// has been written.  The response body may take arbitrarily long to read.
+func WithResponseHeaderTimeout(d time.Duration) HTTPClientOption {
+	return func(_ *http.Client, t *http.Transport) {
+		t.ResponseHeaderTimeout = d
+	}
+}
+
 // WithMethod decorates an HTTPClient, setting a specific HTTP method on each request
 func WithMethod(method string, c HTTPClient) HTTPClient {
 	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
@@ -69,6 +151,60 @@ func WithHeaders(h http.Header, c HTTPClient) HTTPClient {
 	})
 }
 
+// WithHeaderFunc decorates an HTTPClient, computing headers for each request at the time it is made.
+// This is useful for headers that vary per request, such as trace propagation headers derived from the
+// request's context.  Each computed header replaces any existing values for that header, while headers
+// not computed by f are left untouched.
+func WithHeaderFunc(f func(*http.Request) http.Header, c HTTPClient) HTTPClient {
+	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
+		h := f(request)
+		if len(h) > 0 && request.Header == nil {
+			request.Header = make(http.Header, len(h))
+		}
+
+		for k, v := range h {
+			request.Header[textproto.CanonicalMIMEHeaderKey(k)] = v
+		}
+
+		return c.Do(request)
+	})
+}
+
+// DefaultCorrelationIDHeader is the header used by WithCorrelationID when no header is supplied
+const DefaultCorrelationIDHeader = "X-Request-ID"
+
+// WithCorrelationID decorates an HTTPClient, setting a fresh correlation ID on each request so that resource loads
+// can be traced across services.  If header is empty, DefaultCorrelationIDHeader is used.  IDs are produced by gen,
+// or are random UUIDs if gen is nil.  A request that already carries the header keeps its existing ID, so that IDs
+// from upstream propagate.
+func WithCorrelationID(header string, gen func() string, c HTTPClient) HTTPClient {
+	if len(header) == 0 {
+		header = DefaultCorrelationIDHeader
+	}
+
+	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
+		if len(request.Header.Get(header)) == 0 {
+			var id string
+			if gen != nil {
+				id = gen()
+			} else {
+				var err error
+				if id, err = newUUID(); err != nil {
+					return nil, err
+				}
+			}
+
+			if request.Header == nil {
+				request.Header = make(http.Header)
+			}
+
+			request.Header.Set(header, id)
+		}
+
+		return c.Do(request)
+	})
+}
+
 // WithClose decorates an HTTPClient, setting the Request.Close flag for each request
 func WithClose(c HTTPClient) HTTPClient {
 	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
@@ -77,13 +213,88 @@ func WithClose(c HTTPClient) HTTPClient {
 	})
 }
 
-// WithTimeout decorates an HTTPClient, creating a context with a timeout for each request
+// cancelOnClose releases a request's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (coc cancelOnClose) Close() error {
+	err := coc.ReadCloser.Close()
+	coc.cancel()
+	return err
+}
+
+// WithTimeout decorates an HTTPClient, creating a context with a timeout for each request.  The timeout
+// covers the entire transaction, including reading the response body.  The context is released when the
+// response body is closed.
 func WithTimeout(d time.Duration, c HTTPClient) HTTPClient {
 	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
 		ctx, cancel := context.WithTimeout(request.Context(), d)
-		defer cancel()
+		response, err := c.Do(request.WithContext(ctx))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
 
-		return c.Do(request.WithContext(ctx))
+		response.Body = cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+		return response, nil
+	})
+}
+
+// WithMaxResponseBytes decorates an HTTPClient so that no response body yields more than max bytes.  A response
+// whose Content-Length already exceeds max is closed and rejected with a TooLargeError.  Otherwise, reading past max
+// bytes fails with a TooLargeError rather than silently truncating.  Since the limit applies to the body itself,
+// DrainOnClose never reads more than max bytes from a response produced by this decorator either.
+func WithMaxResponseBytes(max int64, c HTTPClient) HTTPClient {
+	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
+		response, err := c.Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		location := request.URL.String()
+		if response.ContentLength > max {
+			response.Body.Close()
+			return nil, TooLargeError{Location: location, Limit: max}
+		}
+
+		response.Body = &maxBytesReader{ReadCloser: response.Body, location: location, limit: max}
+		return response, nil
+	})
+}
+
+// WithDump decorates an HTTPClient, writing each request and its response to w in wire format, as produced by
+// httputil.DumpRequestOut and httputil.DumpResponse.  Bodies are only written when body is true.  In that case
+// bodies are buffered in memory and replaced, so they remain readable downstream.  Writes to w are serialized,
+// so concurrent requests never interleave their dumps.
+func WithDump(w io.Writer, body bool, c HTTPClient) HTTPClient {
+	var lock sync.Mutex
+	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
+		dump, err := httputil.DumpRequestOut(request, body)
+		if err != nil {
+			return nil, err
+		}
+
+		lock.Lock()
+		w.Write(dump)
+		lock.Unlock()
+
+		response, err := c.Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		if dump, err = httputil.DumpResponse(response, body); err != nil {
+			response.Body.Close()
+			return nil, err
+		}
+
+		lock.Lock()
+		w.Write(dump)
+		lock.Unlock()
+
+		return response, nil
 	})
 }
 
@@ -104,6 +315,92 @@ func DrainOnClose(rc io.ReadCloser) io.ReadCloser {
 	return drainOnClose{rc}
 }
 
+type drainOnCloseContext struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (doc drainOnCloseContext) Close() error {
+	drained := make(chan struct{})
+	go func() {
+		io.Copy(ioutil.Discard, doc.ReadCloser)
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-doc.ctx.Done():
+	}
+
+	// closing while a drain is still in progress unblocks it for HTTP response bodies
+	return doc.ReadCloser.Close()
+}
+
+// DrainOnCloseContext is like DrainOnClose, except that draining stops once the given context is done.
+// The underlying io.ReadCloser is closed regardless of whether draining finished.  A fully drained HTTP
+// response body allows its connection to be reused, while one closed early causes the connection to be
+// discarded, so this trades connection reuse for a bound on how long Close can block.
+func DrainOnCloseContext(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	return drainOnCloseContext{ReadCloser: rc, ctx: ctx}
+}
+
+type drainOnCloseTimeout struct {
+	io.ReadCloser
+	d time.Duration
+}
+
+func (doc drainOnCloseTimeout) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), doc.d)
+	defer cancel()
+	return drainOnCloseContext{ReadCloser: doc.ReadCloser, ctx: ctx}.Close()
+}
+
+// DrainOnCloseTimeout is like DrainOnCloseContext, except that draining stops after the given duration.
+// The duration is measured from when Close is invoked.
+func DrainOnCloseTimeout(d time.Duration, rc io.ReadCloser) io.ReadCloser {
+	return drainOnCloseTimeout{ReadCloser: rc, d: d}
+}
+
+// ErrNotModified is returned by conditional opens when the server responds with 304 Not Modified
+var ErrNotModified = errors.New("Resource not modified")
+
+// TooLargeError indicates that a resource's content exceeded the permitted number of bytes
+type TooLargeError struct {
+	Location string
+	Limit    int64
+}
+
+func (e TooLargeError) Error() string {
+	return fmt.Sprintf("Resource %s exceeds the limit of %d bytes", e.Location, e.Limit)
+}
+
+// maxBytesReader fails with a TooLargeError, rather than silently truncating, once more than limit bytes are read
+type maxBytesReader struct {
+	io.ReadCloser
+	location string
+	limit    int64
+	read     int64
+}
+
+func (mbr *maxBytesReader) Read(p []byte) (int, error) {
+	if mbr.read > mbr.limit {
+		return 0, TooLargeError{Location: mbr.location, Limit: mbr.limit}
+	}
+
+	// read at most one byte past the limit, which is enough to detect an oversized stream
+	if remaining := mbr.limit - mbr.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := mbr.ReadCloser.Read(p)
+	mbr.read += int64(n)
+	if mbr.read > mbr.limit {
+		return n - int(mbr.read-mbr.limit), TooLargeError{Location: mbr.location, Limit: mbr.limit}
+	}
+
+	return n, err
+}
+
 // HTTPError represents a failure to obtain an HTTP resource.  This error indicates that a successful
 // HTTP transaction occurred with a non-2XX response code.
 type HTTPError struct {
@@ -119,3 +416,72 @@ func (he HTTPError) Error() string {
 func (he HTTPError) StatusCode() int {
 	return he.Code
 }
+
+// As allows errors.As to obtain a ResolveError from this error.  The ResolveError's Value is the URL,
+// and its Err is this error.
+func (he HTTPError) As(target interface{}) bool {
+	if re, ok := target.(*ResolveError); ok {
+		*re = newResolveError(he.URL, he)
+		return true
+	}
+
+	return false
+}
+
+// ResourceNotFoundError is the HTTPError returned when a server responds with 404 Not Found
+type ResourceNotFoundError struct {
+	HTTPError
+}
+
+// Unwrap exposes the HTTPError, so that errors.As can be used to obtain it
+func (e ResourceNotFoundError) Unwrap() error {
+	return e.HTTPError
+}
+
+// Is allows this error to match ErrNotFound
+func (e ResourceNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// As allows errors.As to obtain a ResolveError from this error.  Unlike the embedded HTTPError's
+// method, the ResolveError's Err is this error, so that it still matches ErrNotFound.
+func (e ResourceNotFoundError) As(target interface{}) bool {
+	if re, ok := target.(*ResolveError); ok {
+		*re = newResolveError(e.URL, e)
+		return true
+	}
+
+	return false
+}
+
+// ContentTypeError indicates that an HTTP resource was served with a media type other than those expected
+type ContentTypeError struct {
+	URL      string
+	Expected string
+	Actual   string
+}
+
+func (e ContentTypeError) Error() string {
+	return fmt.Sprintf("HTTP resource %s has content type %q, expected %q", e.URL, e.Actual, e.Expected)
+}
+
+// UncheckedContentTypeError indicates that a resource's content type was to be restricted, but the
+// resource does not implement ContentTyped
+type UncheckedContentTypeError struct {
+	Location string
+}
+
+func (e UncheckedContentTypeError) Error() string {
+	return fmt.Sprintf("The content type of resource %s cannot be checked", e.Location)
+}
+
+// ShortReadError indicates that an HTTP response body did not match the length advertised by its Content-Length
+type ShortReadError struct {
+	URL      string
+	Expected int64
+	Actual   int64
+}
+
+func (e ShortReadError) Error() string {
+	return fmt.Sprintf("HTTP resource %s had %d bytes, expected %d", e.URL, e.Actual, e.Expected)
+}
@@ -0,0 +1,260 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newConnCountingServer starts a server that records each new connection in conns
+func newConnCountingServer(conns *int32) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		// enough content that an unread remainder is left in the body after a short read
+		response.Write([]byte(strings.Repeat("content ", 1024)))
+	}))
+
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(conns, 1)
+		}
+	}
+
+	server.Start()
+	return server
+}
+
+// resolveAndRead resolves the server's URL through r, reading only part of the content before closing it
+func resolveAndRead(tb testing.TB, r Resolver, u string) {
+	h, err := r.Resolve(u)
+	if err != nil {
+		tb.Fatalf("Resolve failed: %s", err)
+	}
+
+	rc, err := h.Open()
+	if err != nil {
+		tb.Fatalf("Open failed: %s", err)
+	}
+
+	if _, err := rc.Read(make([]byte, 16)); err != nil {
+		tb.Fatalf("Read failed: %s", err)
+	}
+
+	rc.Close()
+}
+
+func TestWithDisableKeepAlives(t *testing.T) {
+	const resolutions = 10
+	testData := []struct {
+		name     string
+		options  []HTTPClientOption
+		expected int32
+	}{
+		{"Default", nil, 1},
+		{"Enabled", []HTTPClientOption{WithDisableKeepAlives(false)}, 1},
+		{"Disabled", []HTTPClientOption{WithDisableKeepAlives(true)}, resolutions},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			var conns int32
+			server := newConnCountingServer(&conns)
+			defer server.Close()
+
+			client := NewHTTPClient(record.options...)
+			defer client.CloseIdleConnections()
+
+			r := HTTPResolver{Client: client}
+			for i := 0; i < resolutions; i++ {
+				resolveAndRead(t, r, server.URL)
+			}
+
+			if actual := atomic.LoadInt32(&conns); actual != record.expected {
+				t.Errorf("Expected %d connections for %d resolutions, got %d", record.expected, resolutions, actual)
+			}
+		})
+	}
+}
+
+func BenchmarkHTTPResolverConnectionReuse(b *testing.B) {
+	var conns int32
+	server := newConnCountingServer(&conns)
+	defer server.Close()
+
+	client := NewHTTPClient()
+	defer client.CloseIdleConnections()
+
+	r := HTTPResolver{Client: client}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resolveAndRead(b, r, server.URL)
+	}
+
+	b.StopTimer()
+	if actual := atomic.LoadInt32(&conns); actual != 1 {
+		b.Errorf("Expected a single connection for %d resolutions, got %d", b.N, actual)
+	}
+
+	b.ReportMetric(float64(atomic.LoadInt32(&conns)), "conns")
+}
+
+// chunkNotifier signals once the first write is received.  The buffer is not embedded, since
+// io.Copy would otherwise use its ReadFrom method and bypass Write.
+type chunkNotifier struct {
+	buffer bytes.Buffer
+	first  chan struct{}
+	once   sync.Once
+}
+
+func (cn *chunkNotifier) Write(p []byte) (int, error) {
+	cn.once.Do(func() { close(cn.first) })
+	return cn.buffer.Write(p)
+}
+
+func TestHTTPChunkedStreaming(t *testing.T) {
+	// the server withholds its second chunk until the client has received the first,
+	// which only happens if the client streams rather than buffers the body
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.Write([]byte("first chunk;"))
+		response.(http.Flusher).Flush()
+
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+
+		response.Write([]byte("second chunk"))
+	}))
+
+	defer server.Close()
+
+	testData := []struct {
+		name   string
+		handle HTTP
+	}{
+		{"Plain", HTTP{URL: server.URL}},
+		{"VerifyLength", HTTP{URL: server.URL, VerifyLength: true}},
+		{"MaxBytes", HTTP{URL: server.URL, MaxBytes: 1024}},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			if _, ok := Interface(record.handle).(Sized); ok {
+				t.Error("HTTP resources should not report a size")
+			}
+
+			cn := &chunkNotifier{first: make(chan struct{})}
+			go func() {
+				select {
+				case <-cn.first:
+					release <- struct{}{}
+				case <-time.After(5 * time.Second):
+				}
+			}()
+
+			start := time.Now()
+			if _, err := record.handle.WriteTo(cn); err != nil {
+				t.Fatalf("WriteTo failed: %s", err)
+			}
+
+			if actual := cn.buffer.String(); actual != "first chunk;second chunk" {
+				t.Errorf("Unexpected content: %q", actual)
+			}
+
+			if elapsed := time.Since(start); elapsed >= 5*time.Second {
+				t.Errorf("The first chunk was not delivered until the response was complete")
+			}
+
+			rc, err := record.handle.Open()
+			if err != nil {
+				t.Fatalf("Open failed: %s", err)
+			}
+
+			defer rc.Close()
+			first := make([]byte, len("first chunk;"))
+			if _, err := io.ReadFull(rc, first); err != nil || string(first) != "first chunk;" {
+				t.Fatalf("Unable to read the first chunk: %q, %v", first, err)
+			}
+
+			select {
+			case release <- struct{}{}:
+			case <-time.After(5 * time.Second):
+				t.Fatal("The first chunk was not delivered until the response was complete")
+			}
+
+			if rest, err := ioutil.ReadAll(rc); err != nil || string(rest) != "second chunk" {
+				t.Errorf("Unable to read the second chunk: %q, %v", rest, err)
+			}
+		})
+	}
+}
+
+func TestHTTPWriteToVerifiesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/error":
+			response.WriteHeader(http.StatusInternalServerError)
+			response.Write([]byte("error body"))
+
+		case "/notmodified":
+			response.WriteHeader(http.StatusNotModified)
+
+		default:
+			response.Header().Set("Content-Type", "text/html")
+			response.Write([]byte("<html></html>"))
+		}
+	}))
+
+	defer server.Close()
+
+	testData := []struct {
+		name   string
+		handle HTTP
+		check  func(error) bool
+	}{
+		{
+			"Status",
+			HTTP{URL: server.URL + "/error"},
+			func(err error) bool {
+				var he HTTPError
+				return errors.As(err, &he) && he.Code == http.StatusInternalServerError
+			},
+		},
+		{
+			"NotModified",
+			HTTP{URL: server.URL + "/notmodified"},
+			func(err error) bool { return errors.Is(err, ErrNotModified) },
+		},
+		{
+			"ContentType",
+			HTTP{URL: server.URL + "/page", ExpectContentType: "application/json"},
+			func(err error) bool {
+				var cte ContentTypeError
+				return errors.As(err, &cte) && cte.Actual == "text/html"
+			},
+		},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			var output bytes.Buffer
+			n, err := record.handle.WriteTo(&output)
+			if !record.check(err) {
+				t.Errorf("Unexpected error from WriteTo: %v", err)
+			}
+
+			if n != 0 || output.Len() != 0 {
+				t.Errorf("Expected nothing to be written for a rejected response, got %q", output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// IndirectScheme is the scheme conventionally mapped to an IndirectResolver
+const IndirectScheme = "indirect"
+
+// DefaultIndirectMaxDepth is the number of pointers an IndirectResolver follows when no MaxDepth is configured
+const DefaultIndirectMaxDepth = 8
+
+// maxPointerSize caps how much of a pointer resource is read, since a pointer only holds a resource string
+const maxPointerSize = 64 * 1024
+
+// IndirectionDepthError is returned when following pointers does not reach a real resource within the depth limit
+type IndirectionDepthError struct {
+	Value    string
+	MaxDepth int
+}
+
+func (e IndirectionDepthError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: more than %d levels of indirection", e.Value, e.MaxDepth)
+}
+
+// IndirectResolver resolves pointer resources, whose content is the resource string of the real resource.
+// For example, "indirect://current-release.txt" loads current-release.txt, whose content might be
+// "s3://bucket/v2/config.json", and then resolves that.  Surrounding whitespace in a pointer is ignored.
+//
+// A pointer may itself refer to another pointer by using the indirect scheme, in which case that pointer is
+// followed as well.  To guard against cycles, at most MaxDepth pointers are followed before an IndirectionDepthError
+// is returned.  Values without the indirect scheme are resolved directly.
+type IndirectResolver struct {
+	// Resolver resolves both pointers and the resource strings they contain.  This field is required.
+	Resolver Resolver
+
+	// MaxDepth is the maximum number of pointers followed.  If nonpositive, DefaultIndirectMaxDepth is used.
+	MaxDepth int
+}
+
+// readPointer loads the resource string held by a pointer resource
+func (ir IndirectResolver) readPointer(v string) (string, error) {
+	r, err := ir.Resolver.Resolve(v)
+	if err != nil {
+		return "", err
+	}
+
+	rc, err := r.Open()
+	if err != nil {
+		return "", err
+	}
+
+	defer rc.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(rc, maxPointerSize))
+	if err != nil {
+		return "", err
+	}
+
+	target := string(bytes.TrimSpace(b))
+	if len(target) == 0 {
+		return "", newResolveError(v, fmt.Errorf("pointer %s is empty", r.Location()))
+	}
+
+	return target, nil
+}
+
+func (ir IndirectResolver) Resolve(v string) (Interface, error) {
+	maxDepth := ir.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultIndirectMaxDepth
+	}
+
+	current := v
+	for depth := 0; strings.HasPrefix(current, IndirectScheme+SchemeSeparator); depth++ {
+		if depth >= maxDepth {
+			return nil, IndirectionDepthError{Value: v, MaxDepth: maxDepth}
+		}
+
+		target, err := ir.readPointer(current[len(IndirectScheme+SchemeSeparator):])
+		if err != nil {
+			return nil, err
+		}
+
+		current = target
+	}
+
+	return ir.Resolver.Resolve(current)
+}
@@ -0,0 +1,31 @@
+package resource
+
+import (
+	"net/url"
+	"strings"
+)
+
+// InlineScheme is the scheme conventionally mapped to an InlineResolver
+const InlineScheme = "inline"
+
+// InlineResolver resolves values that carry both a synthetic location and the content of a resource, e.g.
+// "inline://path/to/x.json?content=%7B%22a%22%3A1%7D".  The returned in-memory handle reports everything
+// between the scheme and the query as its location, and holds the query-unescaped content parameter.  An
+// absent content parameter yields empty content.  This is mainly useful in tests of code that depends on
+// a resource's Location.
+type InlineResolver struct{}
+
+func (r InlineResolver) Resolve(v string) (Interface, error) {
+	_, value := Split(v)
+	location, query := value, ""
+	if i := strings.IndexByte(value, '?'); i >= 0 {
+		location, query = value[:i], value[i+1:]
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return located{Bytes: Bytes(params.Get("content")), location: location}, nil
+}
@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// JitterResolver is a decorator that sleeps for a random duration in [0, MaxJitter) before each resolution.
+// When many processes reload resources on the same schedule, this spreads their requests out over time
+// rather than having them all hit the backing source at once.
+type JitterResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// MaxJitter is the exclusive upper bound on the delay.  If nonpositive, no delay is applied.
+	MaxJitter time.Duration
+
+	// Int63n is the optional source of randomness.  If not supplied, rand.Int63n is used.
+	Int63n func(int64) int64
+}
+
+func (jr JitterResolver) delay() time.Duration {
+	if jr.MaxJitter <= 0 {
+		return 0
+	}
+
+	if jr.Int63n != nil {
+		return time.Duration(jr.Int63n(int64(jr.MaxJitter)))
+	}
+
+	return time.Duration(rand.Int63n(int64(jr.MaxJitter)))
+}
+
+// ResolveContext waits for a random delay and then resolves v.  If ctx is cancelled or its deadline
+// passes before the delay elapses, the context's error is returned and v is never resolved.
+func (jr JitterResolver) ResolveContext(ctx context.Context, v string) (Interface, error) {
+	if d := jr.delay(); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	} else if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return jr.Resolver.Resolve(v)
+}
+
+func (jr JitterResolver) Resolve(v string) (Interface, error) {
+	return jr.ResolveContext(context.Background(), v)
+}
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Meta describes a resource without its content.  This is the JSON document produced by a MetadataResolver.
+// Which fields are populated depends on the kind of resource:
+//
+//   every resource has a Location
+//   in-memory resources, and any other resources that implement Sized, have a Size
+//   files have a Size and LastModified
+//   HTTP resources have whichever of Size, ContentType, LastModified, and ETag the server reports
+//   decorated resources have the metadata of the resource they decorate, less any Size and ContentType
+//     when the decorator transforms the content
+type Meta struct {
+	Location     string     `json:"location"`
+	Size         *int64     `json:"size,omitempty"`
+	ContentType  string     `json:"contentType,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	ETag         string     `json:"etag,omitempty"`
+}
+
+// stat gathers the metadata for a resource.  Decorators are unwrapped through Unwrapper so that the handle
+// they decorate can be inspected, though neither a size nor a content type is reported once a decorator has
+// transformed the content.  Any other handle that implements Sized reports its size.  HTTP resources are
+// queried with a HEAD request.
+func stat(r Interface) (Meta, error) {
+	var (
+		m          = Meta{Location: r.Location()}
+		base       = r
+		size       = int64(-1)
+		transforms bool
+	)
+
+	for {
+		if s, ok := base.(Sized); ok && size < 0 && !transforms {
+			size = s.Size()
+		}
+
+		u, ok := base.(Unwrapper)
+		if !ok {
+			break
+		}
+
+		switch base.(type) {
+		case transformed, gzipped:
+			transforms = true
+		}
+
+		base = u.Unwrap()
+	}
+
+	if cf, ok := base.(CachedFile); ok {
+		base = cf.File
+	}
+
+	switch v := base.(type) {
+	case File:
+		fi, err := os.Stat(string(v))
+		if err != nil {
+			return Meta{}, err
+		}
+
+		size, modTime := fi.Size(), fi.ModTime()
+		m.Size, m.LastModified = &size, &modTime
+
+	case HTTP:
+		v.OpenMethod = http.MethodHead
+		response, err := v.response(nil)
+		if err != nil {
+			return Meta{}, err
+		}
+
+		response.Body.Close()
+		if response.ContentLength >= 0 {
+			size := response.ContentLength
+			m.Size = &size
+		}
+
+		if lm, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+			m.LastModified = &lm
+		}
+
+		m.ContentType = response.Header.Get("Content-Type")
+		m.ETag = response.Header.Get("ETag")
+	}
+
+	if transforms {
+		m.Size, m.ContentType = nil, ""
+	} else if m.Size == nil && size >= 0 {
+		m.Size = &size
+	}
+
+	return m, nil
+}
+
+// MetadataResolver is a decorator that resolves a resource's metadata rather than its content.
+// Each value is resolved through the decorated Resolver, and the resulting resource is inspected
+// without being read.  The returned handle is an in-memory JSON encoding of a Meta.
+type MetadataResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+}
+
+func (mr MetadataResolver) Resolve(v string) (Interface, error) {
+	r, err := mr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := stat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return Bytes(b), nil
+}
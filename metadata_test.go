@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadataResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.Header().Set("Content-Type", "application/json")
+		response.Header().Set("ETag", `"v1"`)
+		response.Write([]byte(`{"key": "value"}`))
+	}))
+
+	defer server.Close()
+
+	path := writeTempFile(t, []byte("file content"))
+	virtual, err := VirtualResolver{
+		Source: func(string) (io.ReadCloser, int64, error) {
+			return ioutil.NopCloser(strings.NewReader("virtual")), 7, nil
+		},
+	}.Resolve("virtual")
+
+	if err != nil {
+		t.Fatalf("Resolve failed: %s", err)
+	}
+
+	testData := []struct {
+		name        string
+		handle      Interface
+		size        int64
+		contentType string
+		etag        string
+		modified    bool
+	}{
+		{"String", String("hello"), 5, "", "", false},
+		{"File", File(path), 12, "", "", true},
+		{"CachedFile", CachedFile{File: File(path)}, 12, "", "", true},
+		{"Virtual", virtual, 7, "", "", false},
+		{"HTTP", HTTP{URL: server.URL}, 16, "application/json", `"v1"`, false},
+		{"DecoratedString", WithMetadata(String("hello"), nil), 5, "", "", false},
+		{"DecoratedFile", WithReadTimeout(time.Second, File(path)), 12, "", "", true},
+		{"DecoratedHTTP", WithMetadata(WithReadTimeout(time.Second, HTTP{URL: server.URL}), nil), 16, "application/json", `"v1"`, false},
+		{"Transformed", GzipWriteTo(HTTP{URL: server.URL}, -1), -1, "", `"v1"`, false},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			r, err := MetadataResolver{
+				Resolver: ResolverFunc(func(string) (Interface, error) { return record.handle, nil }),
+			}.Resolve(record.handle.Location())
+
+			if err != nil {
+				t.Fatalf("Resolve failed: %s", err)
+			}
+
+			var m Meta
+			if err := json.Unmarshal([]byte(readAll(t, r)), &m); err != nil {
+				t.Fatalf("Unable to decode the metadata: %s", err)
+			}
+
+			if m.Location != record.handle.Location() {
+				t.Errorf("Expected location %s, got %s", record.handle.Location(), m.Location)
+			}
+
+			if record.size < 0 {
+				if m.Size != nil {
+					t.Errorf("Expected no size, got %d", *m.Size)
+				}
+			} else if m.Size == nil || *m.Size != record.size {
+				t.Errorf("Expected size %d, got %v", record.size, m.Size)
+			}
+
+			if m.ContentType != record.contentType {
+				t.Errorf("Expected content type %q, got %q", record.contentType, m.ContentType)
+			}
+
+			if m.ETag != record.etag {
+				t.Errorf("Expected etag %q, got %q", record.etag, m.ETag)
+			}
+
+			if (m.LastModified != nil) != record.modified {
+				t.Errorf("Unexpected last modified time: %v", m.LastModified)
+			}
+		})
+	}
+}
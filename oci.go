@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// OCIScheme is the scheme conventionally mapped to an OCIResolver
+const OCIScheme = "oci"
+
+// DefaultOCITag is the tag used when an OCI reference supplies neither a tag nor a digest
+const DefaultOCITag = "latest"
+
+// ErrInvalidOCIValue indicates that a value did not have the form registry/repository[:tag][@digest]
+var ErrInvalidOCIValue = errors.New("OCI resources must have the form registry/repository[:tag][@digest]")
+
+// OCIReference identifies an artifact stored in an OCI registry
+type OCIReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// String returns the reference in the conventional registry/repository[:tag][@digest] form
+func (ref OCIReference) String() string {
+	s := ref.Registry + "/" + ref.Repository
+	if len(ref.Tag) > 0 {
+		s += ":" + ref.Tag
+	}
+
+	if len(ref.Digest) > 0 {
+		s += "@" + ref.Digest
+	}
+
+	return s
+}
+
+// ParseOCIReference parses a value of the form "oci://registry/repository[:tag][@digest]".  The scheme is
+// optional.  When neither a tag nor a digest is supplied, DefaultOCITag is used.
+func ParseOCIReference(v string) (OCIReference, error) {
+	var ref OCIReference
+	_, value := Split(v)
+	if i := strings.IndexByte(value, '@'); i >= 0 {
+		value, ref.Digest = value[:i], value[i+1:]
+		if len(ref.Digest) == 0 {
+			return OCIReference{}, ErrInvalidOCIValue
+		}
+	}
+
+	// a colon after the last slash separates the tag, while any earlier colon belongs to a registry port
+	if i := strings.LastIndexByte(value, ':'); i > strings.LastIndexByte(value, '/') {
+		value, ref.Tag = value[:i], value[i+1:]
+		if len(ref.Tag) == 0 {
+			return OCIReference{}, ErrInvalidOCIValue
+		}
+	}
+
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) < 2 || len(parts[0]) == 0 || len(strings.Trim(parts[1], "/")) == 0 {
+		return OCIReference{}, ErrInvalidOCIValue
+	}
+
+	ref.Registry, ref.Repository = parts[0], strings.Trim(parts[1], "/")
+	if len(ref.Tag) == 0 && len(ref.Digest) == 0 {
+		ref.Tag = DefaultOCITag
+	}
+
+	return ref, nil
+}
+
+// OCIClient is the minimal behavior required to pull artifacts from an OCI registry.  Implementations
+// typically adapt an ORAS or other registry client, and are responsible for authentication and for
+// choosing which blob of a multi-layer artifact to return.
+type OCIClient interface {
+	// Pull returns the content of the artifact blob identified by ref.  If the artifact does not exist,
+	// Pull should return an error for which errors.Is(err, ErrNotFound) is true.
+	Pull(ctx context.Context, ref OCIReference) (io.ReadCloser, error)
+}
+
+// OCI is a resource stored as an artifact in an OCI registry.  The artifact is pulled each time
+// this resource is opened.
+type OCI struct {
+	Reference OCIReference
+	Client    OCIClient
+}
+
+func (o OCI) Location() string {
+	return OCIScheme + SchemeSeparator + o.Reference.String()
+}
+
+func (o OCI) Open() (io.ReadCloser, error) {
+	return o.Client.Pull(context.Background(), o.Reference)
+}
+
+func (o OCI) WriteTo(w io.Writer) (int64, error) {
+	rc, err := o.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// OCIResolver resolves artifacts stored in OCI registries.  Values have the form
+// "oci://registry/repository:tag" or "oci://registry/repository@sha256:...".  Resolution only parses
+// the reference.  The artifact itself is pulled through Client when the returned handle is opened.
+type OCIResolver struct {
+	// Client is the registry client used to pull artifacts.  This field is required.
+	Client OCIClient
+}
+
+func (r OCIResolver) Resolve(v string) (Interface, error) {
+	ref, err := ParseOCIReference(v)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return OCI{Reference: ref, Client: r.Client}, nil
+}
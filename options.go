@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"net/http"
+	"time"
+)
+
+// Options holds the per-call settings supplied to ResolveOpts.  Each resolver honors whichever
+// options make sense for it, and ignores the rest.
+type Options struct {
+	// Timeout limits the time spent loading the resource
+	Timeout time.Duration
+
+	// Header holds extra headers for resources, such as HTTP, that support them
+	Header http.Header
+
+	// MaxBytes limits the size of the resource's content
+	MaxBytes int64
+}
+
+// Option configures the Options for a single resolution
+type Option func(*Options)
+
+// WithRequestTimeout limits the time spent loading the resource
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// WithRequestHeader adds a header to requests made for the resource
+func WithRequestHeader(name, value string) Option {
+	return func(o *Options) {
+		if o.Header == nil {
+			o.Header = make(http.Header)
+		}
+
+		o.Header.Add(name, value)
+	}
+}
+
+// WithMaxBytes limits the size of the resource's content.  Reading more than n bytes fails with a TooLargeError.
+func WithMaxBytes(n int64) Option {
+	return func(o *Options) {
+		o.MaxBytes = n
+	}
+}
+
+// NewOptions applies a sequence of Option functions to an empty Options
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, f := range opts {
+		f(&o)
+	}
+
+	return o
+}
+
+// ResolverWithOptions is an optional interface implemented by resolvers that accept per-call options
+type ResolverWithOptions interface {
+	Resolver
+
+	// ResolveOpts is like Resolve, but applies the given options to the resulting handle
+	ResolveOpts(v string, opts ...Option) (Interface, error)
+}
+
+// ResolveOpts resolves v, passing the given options if r implements ResolverWithOptions.
+// Otherwise, the options are ignored and r.Resolve is used.
+func ResolveOpts(r Resolver, v string, opts ...Option) (Interface, error) {
+	if rwo, ok := r.(ResolverWithOptions); ok {
+		return rwo.ResolveOpts(v, opts...)
+	}
+
+	return r.Resolve(v)
+}
@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// DefaultOverridePrefix is the prefix of the environment variables consulted by an OverrideResolver
+const DefaultOverridePrefix = "RESOURCE_OVERRIDE_"
+
+// OverrideResolver is a decorator that allows the environment to replace resource values before they
+// are resolved.  For each value, an override key is chosen, and if the environment variable named by
+// Prefix followed by that key is set to a nonempty string, that string is resolved in place of the value.
+// Otherwise, the original value is resolved unchanged.
+//
+// The override key for a value is taken from Keys, if present there.  Otherwise, it is derived from the
+// base name of the value without its extension, uppercased, with every character other than a letter or
+// digit replaced by an underscore.  For example, with the default prefix, "file:///etc/app-config.json"
+// may be overridden by setting RESOURCE_OVERRIDE_APP_CONFIG=s3://bucket/app.json.
+//
+// Overrides are applied once:  an override value is never itself overridden.
+type OverrideResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Prefix is the prefix of override environment variables.  If not supplied, DefaultOverridePrefix is used.
+	Prefix string
+
+	// Keys is the optional explicit mapping of resource values onto override keys.  Entries in this map
+	// take precedence over derived keys.
+	Keys map[string]string
+
+	// Lookup is the optional function used to read overrides.  If not supplied, os.LookupEnv is used.
+	Lookup func(string) (string, bool)
+}
+
+// deriveOverrideKey computes the override key for a value from its base name
+func deriveOverrideKey(v string) string {
+	_, value := Split(v)
+	base := path.Base(strings.TrimRight(value, "/"))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	return strings.Map(
+		func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+				return r
+			default:
+				return '_'
+			}
+		},
+		base,
+	)
+}
+
+// Key returns the name of the environment variable that overrides the given value
+func (or OverrideResolver) Key(v string) string {
+	prefix := or.Prefix
+	if len(prefix) == 0 {
+		prefix = DefaultOverridePrefix
+	}
+
+	if key, ok := or.Keys[v]; ok {
+		return prefix + key
+	}
+
+	return prefix + deriveOverrideKey(v)
+}
+
+func (or OverrideResolver) Resolve(v string) (Interface, error) {
+	lookup := or.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	if override, ok := lookup(or.Key(v)); ok && len(override) > 0 {
+		return or.Resolver.Resolve(override)
+	}
+
+	return or.Resolver.Resolve(v)
+}
@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxPages is the maximum number of pages read by an HTTP resource that follows pagination,
+// when no MaxPages is configured.
+const DefaultMaxPages = 100
+
+// nextLink returns the target of the first RFC 5988 Link header with rel="next", resolved against
+// the response's request URL.  If there is no such link, this function returns the empty string.
+func nextLink(response *http.Response) string {
+	for _, header := range response.Header["Link"] {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(param, "=", 2)
+				if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "rel") {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(kv[1]), `"`)) {
+					if !strings.EqualFold(rel, "next") {
+						continue
+					}
+
+					target = target[1 : len(target)-1]
+					if response.Request != nil && response.Request.URL != nil {
+						if u, err := response.Request.URL.Parse(target); err == nil {
+							return u.String()
+						}
+					}
+
+					return target
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// pageReader reads the body of each page of a paginated HTTP resource in turn
+type pageReader struct {
+	h       HTTP
+	current *http.Response
+	body    io.ReadCloser
+	pages   int
+}
+
+func (pr *pageReader) maxPages() int {
+	if pr.h.MaxPages > 0 {
+		return pr.h.MaxPages
+	}
+
+	return DefaultMaxPages
+}
+
+func (pr *pageReader) Read(p []byte) (int, error) {
+	for {
+		n, err := pr.body.Read(p)
+		if err != io.EOF || pr.pages >= pr.maxPages() {
+			return n, err
+		}
+
+		next := nextLink(pr.current)
+		if len(next) == 0 {
+			return n, err
+		}
+
+		pr.body.Close()
+		response, err := pr.h.fetch(next, nil)
+		if err != nil {
+			// leave an exhausted body in place, so that Close remains safe
+			pr.body = http.NoBody
+			return n, err
+		}
+
+		pr.current, pr.body = response, response.Body
+		pr.pages++
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close drains and closes only the current page.  Pages that have not been fetched yet never are.
+func (pr *pageReader) Close() error {
+	return DrainOnClose(pr.body).Close()
+}
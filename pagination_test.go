@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newPaginatedServer serves pages of the form "page N;" with Link headers pointing at the next page
+func newPaginatedServer(pages int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(requests, 1)
+		page, _ := strconv.Atoi(request.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		if page < pages {
+			response.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+		}
+
+		// a large page, so that a partial read leaves most of it unread
+		fmt.Fprintf(response, "page %d;%s", page, strings.Repeat(" ", 64*1024))
+	}))
+}
+
+func TestFollowPaginationReadsAllPages(t *testing.T) {
+	var requests int32
+	server := newPaginatedServer(3, &requests)
+	defer server.Close()
+
+	content := readAll(t, HTTP{URL: server.URL + "/items", FollowPagination: true})
+	for page := 1; page <= 3; page++ {
+		if !strings.Contains(content, fmt.Sprintf("page %d;", page)) {
+			t.Errorf("Expected the content to include page %d", page)
+		}
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("Expected 3 requests, got %d", n)
+	}
+}
+
+func TestFollowPaginationCloseEarly(t *testing.T) {
+	var requests int32
+	server := newPaginatedServer(3, &requests)
+	defer server.Close()
+
+	rc, err := HTTP{URL: server.URL + "/items", FollowPagination: true}.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	if _, err := rc.Read(make([]byte, 4)); err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+
+	if err := rc.Close(); err != nil {
+		t.Errorf("Close failed: %s", err)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("Closing after a partial read should not fetch further pages, but %d requests were made", n)
+	}
+}
@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+)
+
+// DefaultRandomMaxSize is the largest number of bytes a RandomResolver generates when no MaxSize is configured
+const DefaultRandomMaxSize = 4 << 20
+
+// RandomResolver resolves values such as "random://32" as the given number of cryptographically random
+// bytes.  Any scheme is ignored by this resolver.  Each resolution generates new content, and the returned
+// in-memory handle reports the original value as its location rather than the generated content.
+type RandomResolver struct {
+	// MaxSize is the largest number of bytes a value may request.  If nonpositive, DefaultRandomMaxSize is used.
+	MaxSize int
+}
+
+// size parses the number of random bytes requested by a value
+func (r RandomResolver) size(v string) (int, error) {
+	_, value := Split(v)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, newResolveError(v, err)
+	}
+
+	if n < 0 {
+		return 0, newResolveError(v, fmt.Errorf("negative size %d", n))
+	}
+
+	max := r.MaxSize
+	if max <= 0 {
+		max = DefaultRandomMaxSize
+	}
+
+	if n > max {
+		return 0, newResolveError(v, fmt.Errorf("size %d exceeds the maximum of %d", n, max))
+	}
+
+	return n, nil
+}
+
+func (r RandomResolver) Resolve(v string) (Interface, error) {
+	n, err := r.size(v)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return located{Bytes: Bytes(b), location: v}, nil
+}
+
+// Validate checks that v requests a valid number of bytes, without generating any
+func (r RandomResolver) Validate(v string) error {
+	_, err := r.size(v)
+	return err
+}
+
+// newUUID generates a random, version 4 UUID in its canonical string form
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
+
+// UUIDResolver resolves every value, such as "uuid://", as a freshly generated version 4 UUID string.
+// The value itself is ignored apart from being reported as the location of the returned in-memory handle.
+type UUIDResolver struct{}
+
+func (r UUIDResolver) Resolve(v string) (Interface, error) {
+	u, err := newUUID()
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return located{Bytes: Bytes(u), location: v}, nil
+}
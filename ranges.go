@@ -0,0 +1,158 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// errRangesUnsupported indicates that a server ignored a range request
+var errRangesUnsupported = errors.New("Server does not support range requests")
+
+type parallelRanges struct {
+	HTTP
+	chunks int
+}
+
+// probe issues a HEAD request to learn the size of the resource and whether the server accepts byte ranges.
+// A failed HEAD request is treated as a lack of range support, since some servers reject HEAD outright.
+// Any genuine problem with the resource is then reported by the sequential download.
+func (pr parallelRanges) probe() (int64, bool) {
+	head := pr.HTTP
+	head.OpenMethod = http.MethodHead
+	response, err := head.fetch(head.URL, nil)
+	if err != nil {
+		return 0, false
+	}
+
+	response.Body.Close()
+	supported := response.ContentLength > 0
+	if supported {
+		supported = false
+		for _, unit := range strings.Split(response.Header.Get("Accept-Ranges"), ",") {
+			if strings.EqualFold(strings.TrimSpace(unit), "bytes") {
+				supported = true
+			}
+		}
+	}
+
+	return response.ContentLength, supported
+}
+
+// fetchRange reads a single byte range of the resource into segment, which must be exactly the size of the range
+func (pr parallelRanges) fetchRange(start int64, segment []byte) error {
+	header := make(http.Header)
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, start+int64(len(segment))-1))
+	response, err := pr.HTTP.fetch(pr.URL, header)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusPartialContent {
+		// the body is likely the entire resource, so it is closed without being drained
+		response.Body.Close()
+		return errRangesUnsupported
+	}
+
+	defer DrainOnClose(response.Body).Close()
+	_, err = io.ReadFull(response.Body, segment)
+	return err
+}
+
+// download fetches the resource as concurrent byte ranges.  A nil slice with a nil error indicates that
+// the server does not support ranges, and the resource should be read sequentially.
+func (pr parallelRanges) download() ([]byte, error) {
+	size, supported := pr.probe()
+	if !supported {
+		return nil, nil
+	}
+
+	if pr.MaxBytes > 0 && size > pr.MaxBytes {
+		return nil, TooLargeError{Location: pr.URL, Limit: pr.MaxBytes}
+	}
+
+	var (
+		content   = make([]byte, size)
+		chunkSize = (size + int64(pr.chunks) - 1) / int64(pr.chunks)
+		errs      = make([]error, 0, pr.chunks)
+		lock      sync.Mutex
+		wg        sync.WaitGroup
+	)
+
+	for start := int64(0); start < size; start += chunkSize {
+		end := start + chunkSize
+		if end > size {
+			end = size
+		}
+
+		wg.Add(1)
+		go func(start int64, segment []byte) {
+			defer wg.Done()
+			if err := pr.fetchRange(start, segment); err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}(start, content[start:end])
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		if err == errRangesUnsupported {
+			return nil, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return content, nil
+}
+
+func (pr parallelRanges) Unwrap() Interface {
+	return pr.HTTP
+}
+
+func (pr parallelRanges) Open() (io.ReadCloser, error) {
+	content, err := pr.download()
+	if err != nil {
+		return nil, err
+	} else if content == nil {
+		return pr.HTTP.Open()
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (pr parallelRanges) WriteTo(w io.Writer) (int64, error) {
+	content, err := pr.download()
+	if err != nil {
+		return 0, err
+	} else if content == nil {
+		return pr.HTTP.WriteTo(w)
+	}
+
+	return Bytes(content).WriteTo(w)
+}
+
+// WithParallelRanges decorates an HTTP resource so that its content is downloaded as the given number of
+// byte ranges, requested concurrently and assembled in memory.  Before downloading, a HEAD request determines
+// the size of the resource and whether the server advertises "Accept-Ranges: bytes".  If it does not, if the
+// HEAD request fails, or if any range request is answered with something other than 206 Partial Content,
+// the resource is downloaded sequentially instead.
+//
+// Parallel downloads only apply to plain GET requests, so a resource with a different OpenMethod or with
+// FollowPagination enabled is returned as is, as is any resource when chunks is less than 2.
+func WithParallelRanges(chunks int, h HTTP) Interface {
+	if chunks < 2 || h.FollowPagination || (len(h.OpenMethod) > 0 && h.OpenMethod != http.MethodGet) {
+		return h
+	}
+
+	return parallelRanges{HTTP: h, chunks: chunks}
+}
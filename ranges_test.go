@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var rangesContent = strings.Repeat("0123456789", 1000)
+
+func TestWithParallelRanges(t *testing.T) {
+	var ranges int32
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if len(request.Header.Get("Range")) > 0 {
+			atomic.AddInt32(&ranges, 1)
+		}
+
+		http.ServeContent(response, request, "content", time.Time{}, strings.NewReader(rangesContent))
+	}))
+
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	if _, err := WithParallelRanges(4, HTTP{URL: server.URL}).WriteTo(&buffer); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+
+	if buffer.String() != rangesContent {
+		t.Error("The assembled content does not match the original")
+	}
+
+	if actual := atomic.LoadInt32(&ranges); actual != 4 {
+		t.Errorf("Expected 4 range requests, got %d", actual)
+	}
+}
+
+func TestWithParallelRangesFallback(t *testing.T) {
+	testData := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			"HeadRejected",
+			func(response http.ResponseWriter, request *http.Request) {
+				if request.Method == http.MethodHead {
+					response.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+
+				response.Write([]byte(rangesContent))
+			},
+		},
+		{
+			"RangeIgnored",
+			func(response http.ResponseWriter, request *http.Request) {
+				response.Header().Set("Accept-Ranges", "bytes")
+				response.Header().Set("Content-Length", strconv.Itoa(len(rangesContent)))
+				if request.Method != http.MethodHead {
+					response.Write([]byte(rangesContent))
+				}
+			},
+		},
+		{
+			"NoAcceptRanges",
+			func(response http.ResponseWriter, request *http.Request) {
+				response.Write([]byte(rangesContent))
+			},
+		},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			server := httptest.NewServer(record.handler)
+			defer server.Close()
+
+			r := WithParallelRanges(4, HTTP{URL: server.URL})
+			if actual := readAll(t, r); actual != rangesContent {
+				t.Error("The content read by Open does not match the original")
+			}
+
+			var buffer bytes.Buffer
+			if _, err := r.WriteTo(&buffer); err != nil {
+				t.Fatalf("WriteTo failed: %s", err)
+			}
+
+			if buffer.String() != rangesContent {
+				t.Error("The content written by WriteTo does not match the original")
+			}
+		})
+	}
+}
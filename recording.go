@@ -0,0 +1,117 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// RecordingMode determines whether a RecordingResolver records or replays resources
+type RecordingMode int
+
+const (
+	// Record causes resources to be loaded from their real sources and saved
+	Record RecordingMode = iota
+
+	// Replay causes resources to be served only from previously saved recordings
+	Replay
+)
+
+// MissingCassetteError is returned in Replay mode when no recording exists for a value
+type MissingCassetteError struct {
+	Value string
+	Path  string
+}
+
+func (e MissingCassetteError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: no recording at %s", e.Value, e.Path)
+}
+
+// cassette is the metadata saved alongside each recorded resource
+type cassette struct {
+	Value    string `json:"value"`
+	Location string `json:"location"`
+}
+
+// recorded is a resource played back from a recording.  It reports the location of the original resource.
+type recorded struct {
+	File
+	location string
+}
+
+func (r recorded) Location() string {
+	return r.location
+}
+
+// RecordingResolver is a decorator that records resources for later replay, in the manner of VCR.
+// In Record mode, each value is resolved and loaded through the decorated Resolver, and its content and
+// metadata are saved to Dir.  In Replay mode, the decorated Resolver is never used, and each value is served
+// from Dir.  Recordings are keyed by the resource value, so the same values must be used in both modes.
+//
+// This is intended to make tests of code that loads remote resources fast and deterministic.
+type RecordingResolver struct {
+	// Resolver is the decorated Resolver used in Record mode
+	Resolver Resolver
+
+	// Dir is the directory that holds recordings.  This field is required.
+	Dir string
+
+	// Mode determines whether this resolver records or replays.  The default is Record.
+	Mode RecordingMode
+}
+
+func (rr RecordingResolver) paths(v string) (body, metadata string) {
+	base := filepath.Join(rr.Dir, cacheKey(v))
+	return base + ".body", base + ".json"
+}
+
+func (rr RecordingResolver) record(v string) (Interface, error) {
+	r, err := rr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	body, metadata := rr.paths(v)
+	if _, err := SaveTo(r, body); err != nil {
+		return nil, err
+	}
+
+	c := cassette{Value: v, Location: r.Location()}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(metadata, data, 0644); err != nil {
+		return nil, err
+	}
+
+	return recorded{File: File(body), location: c.Location}, nil
+}
+
+func (rr RecordingResolver) replay(v string) (Interface, error) {
+	body, metadata := rr.paths(v)
+	data, err := ioutil.ReadFile(metadata)
+	if os.IsNotExist(err) {
+		return nil, MissingCassetteError{Value: v, Path: metadata}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return recorded{File: File(body), location: c.Location}, nil
+}
+
+func (rr RecordingResolver) Resolve(v string) (Interface, error) {
+	if rr.Mode == Replay {
+		return rr.replay(v)
+	}
+
+	return rr.record(v)
+}
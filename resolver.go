@@ -1,9 +1,17 @@
 package resource
 
 import (
+	"encoding/base32"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
+	"os"
+	"os/user"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // Resolver is the strategy used to turn strings into resource handles.
@@ -21,6 +29,99 @@ func (rf ResolverFunc) Resolve(v string) (Interface, error) {
 	return rf(v)
 }
 
+// Middleware decorates a Resolver, typically by wrapping its Resolve method.  A Middleware may
+// alter the value, short-circuit resolution, or decorate the resulting handle.
+type Middleware func(next Resolver) Resolver
+
+// Use applies a chain of middleware to a Resolver.  The first middleware is outermost, so it sees
+// each value first and each result last.  For example, Use(r, a, b) is equivalent to a(b(r)).
+func Use(r Resolver, mws ...Middleware) Resolver {
+	for i := len(mws) - 1; i >= 0; i-- {
+		r = mws[i](r)
+	}
+
+	return r
+}
+
+// ResolveError is returned when a resolver fails due to some underlying error, such as a malformed
+// URL or an invalid base64 encoding.  The underlying error is available via Unwrap, so errors.Is and
+// errors.As can be used to examine the cause.
+type ResolveError struct {
+	Value  string
+	Scheme string
+	Err    error
+}
+
+// newResolveError wraps err in a ResolveError for the given resource value
+func newResolveError(v string, err error) ResolveError {
+	scheme, _ := Split(v)
+	return ResolveError{Value: v, Scheme: scheme, Err: err}
+}
+
+func (e ResolveError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: %s", e.Value, e.Err)
+}
+
+func (e ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNotFound is the sentinel for missing resources, regardless of scheme.  Errors indicating that a
+// resource does not exist satisfy errors.Is(err, ErrNotFound).  This is the same value as os.ErrNotExist,
+// so errors from opening missing files satisfy this check as well.
+var ErrNotFound = os.ErrNotExist
+
+// NotFoundError is returned when a resolver has no resource for a given value
+type NotFoundError struct {
+	Value string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: resource not found", e.Value)
+}
+
+// Is allows this error to match ErrNotFound
+func (e NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// NopResolver resolves every value as an empty, in-memory resource.  Useful as a stub in tests.
+type NopResolver struct{}
+
+func (r NopResolver) Resolve(string) (Interface, error) {
+	return String(""), nil
+}
+
+// FixedResolver always returns the same resource handle and error, regardless of the value.
+// Useful as a stub in tests.
+type FixedResolver struct {
+	// Resource is the handle returned by Resolve
+	Resource Interface
+
+	// Err is the error returned by Resolve.  If supplied, Resource is ignored.
+	Err error
+}
+
+func (r FixedResolver) Resolve(string) (Interface, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	return r.Resource, nil
+}
+
+// MapResolver resolves values by exact match against a set of preconfigured resource handles.
+// Values that have no mapping result in a NotFoundError.  Useful as a stub in tests.
+type MapResolver map[string]Interface
+
+func (r MapResolver) Resolve(v string) (Interface, error) {
+	if h, ok := r[v]; ok {
+		return h, nil
+	}
+
+	return nil, NotFoundError{Value: v}
+}
+
 // StringResolver resolves values as in-memory strings rather than external locations.
 // This resolver ignores any scheme associated with the value, allowing it to be mapped
 // to any desired scheme.
@@ -45,46 +146,430 @@ func (r BytesResolver) Resolve(v string) (Interface, error) {
 		enc = base64.StdEncoding
 	}
 
-	_, v = Split(v)
-	b, err := enc.DecodeString(v)
+	_, value := Split(v)
+	b, err := enc.DecodeString(value)
 	if err != nil {
-		return nil, err
+		return nil, newResolveError(v, err)
+	}
+
+	return Bytes(b), nil
+}
+
+// Validate checks that v decodes successfully
+func (r BytesResolver) Validate(v string) error {
+	_, err := r.Resolve(v)
+	return err
+}
+
+// Base64URLResolver resolves values as in-memory bytes encoded with the URL-safe base64 alphabet,
+// as used by JWTs and other web tokens.  Padding is optional.  Any scheme is ignored by this resolver.
+type Base64URLResolver struct{}
+
+func (r Base64URLResolver) Resolve(v string) (Interface, error) {
+	_, value := Split(v)
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return Bytes(b), nil
+}
+
+// Validate checks that v decodes successfully
+func (r Base64URLResolver) Validate(v string) error {
+	_, err := r.Resolve(v)
+	return err
+}
+
+// Base32Resolver resolves values as in-memory bytes encoded as base32 strings, as used by TOTP seeds
+// and some tokens.  The choice of encoding is configurable, and defaults to base32.StdEncoding.  Any
+// scheme is ignored by this resolver.
+type Base32Resolver struct {
+	// Encoding is the base32 encoding to use.  If not supplied, base32.StdEncoding is used.
+	Encoding *base32.Encoding
+}
+
+func (r Base32Resolver) Resolve(v string) (Interface, error) {
+	enc := r.Encoding
+	if enc == nil {
+		enc = base32.StdEncoding
+	}
+
+	_, value := Split(v)
+	b, err := enc.DecodeString(value)
+	if err != nil {
+		return nil, newResolveError(v, err)
 	}
 
 	return Bytes(b), nil
 }
 
+// Validate checks that v decodes successfully
+func (r Base32Resolver) Validate(v string) error {
+	_, err := r.Resolve(v)
+	return err
+}
+
 // FileResolver resolves values as file system paths, relative to an optional Root directory.
-// Any scheme is ignored by this resolver.
+// Values with the FileScheme are treated as file URIs, and so are percent-decoded.  Any other
+// scheme is ignored by this resolver.
 type FileResolver struct {
 	// Root is the optional file system path that acts as the logical root directory
 	// for any resource strings this instance resolves.  If not supplied, no root is assumed.
 	Root string
+
+	// RegularOnly rejects paths that exist but are not regular files, such as named pipes and devices.
+	// Opening such files can block indefinitely.  Paths that do not exist are not rejected.
+	RegularOnly bool
+
+	// ExpandHome enables expansion of a leading "~" or "~user" to the corresponding home directory.
+	// A "~" anywhere other than the start of the path is left alone.  Since home directories are absolute,
+	// Root does not apply to expanded paths.
+	ExpandHome bool
+}
+
+// NewExecutableFileResolver creates a FileResolver whose Root is the directory containing the running executable,
+// with symbolic links evaluated, so that relative paths refer to files shipped alongside the binary.  If that
+// directory cannot be determined, the returned FileResolver has no Root, so relative paths are resolved against
+// the current working directory, and the error is returned as a warning.
+func NewExecutableFileResolver() (FileResolver, error) {
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+
+	if err != nil {
+		return FileResolver{}, err
+	}
+
+	return FileResolver{Root: filepath.Dir(exe)}, nil
+}
+
+// expandHome expands a leading "~" or "~user" in p.  The returned flag indicates whether expansion occurred.
+func expandHome(p string) (string, bool, error) {
+	if !strings.HasPrefix(p, "~") {
+		return p, false, nil
+	}
+
+	name, rest := p[1:], ""
+	if i := strings.IndexAny(name, `/\`); i >= 0 {
+		name, rest = name[:i], name[i+1:]
+	}
+
+	var home string
+	if len(name) == 0 {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return "", false, err
+		}
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", false, err
+		}
+
+		home = u.HomeDir
+	}
+
+	return filepath.Join(home, rest), true, nil
 }
 
 func (r FileResolver) Resolve(v string) (Interface, error) {
-	_, v = Split(v)
-	p, err := filepath.Abs(filepath.Join(r.Root, v))
+	scheme, path := Split(v)
+	if scheme == FileScheme {
+		// file URIs percent-encode characters such as spaces, while bare paths may legitimately contain '%'
+		unescaped, err := url.PathUnescape(path)
+		if err != nil {
+			return nil, newResolveError(v, err)
+		}
+
+		path = unescaped
+	}
+
+	// an empty path would otherwise resolve to the root, or the current directory
+	if len(strings.TrimSpace(path)) == 0 {
+		return nil, EmptyValueError{Value: v}
+	}
+
+	root := r.Root
+	if r.ExpandHome {
+		expanded, ok, err := expandHome(path)
+		if err != nil {
+			return nil, newResolveError(v, err)
+		}
+
+		if ok {
+			path, root = expanded, ""
+		}
+	}
+
+	p, err := filepath.Abs(filepath.Join(root, path))
 	if err != nil {
-		return nil, err
+		return nil, newResolveError(v, err)
+	}
+
+	if r.RegularOnly {
+		if fi, err := os.Stat(p); err == nil && !fi.Mode().IsRegular() {
+			return nil, NotRegularFileError{Path: p, Mode: fi.Mode()}
+		}
 	}
 
 	return File(p), nil
 }
 
+// Validate checks that the file referred to by v exists
+func (r FileResolver) Validate(v string) error {
+	f, err := r.Resolve(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(string(f.(File)))
+	return err
+}
+
+// SearchPathError is returned when a SearchPathResolver cannot find a file in any of its roots
+type SearchPathError struct {
+	Value string
+	Roots []string
+}
+
+func (e SearchPathError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: not found in any of [%s]", e.Value, strings.Join(e.Roots, ", "))
+}
+
+// Is allows this error to match ErrNotFound
+func (e SearchPathError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// SearchPathResolver resolves relative file paths against an ordered list of root directories, returning
+// the first file that exists, much like a PATH lookup.  Absolute paths are resolved as is.  Any scheme
+// is ignored by this resolver.
+type SearchPathResolver struct {
+	// Roots are the directories searched, in order of precedence
+	Roots []string
+}
+
+func (r SearchPathResolver) Resolve(v string) (Interface, error) {
+	_, path := Split(v)
+	if filepath.IsAbs(path) {
+		return File(filepath.Clean(path)), nil
+	}
+
+	for _, root := range r.Roots {
+		p, err := filepath.Abs(filepath.Join(root, path))
+		if err != nil {
+			return nil, newResolveError(v, err)
+		}
+
+		if _, err := os.Stat(p); err == nil {
+			return File(p), nil
+		}
+	}
+
+	return nil, SearchPathError{Value: v, Roots: r.Roots}
+}
+
+// HeaderParamPrefix is the prefix of the query parameters that HTTPResolver.HeaderParams turns into headers
+const HeaderParamPrefix = "header."
+
+// extractHeaderParams removes the query parameters beginning with HeaderParamPrefix from a URL, returning
+// the remaining URL and the headers those parameters described.  All other parameters are preserved exactly.
+func extractHeaderParams(v string) (string, http.Header, error) {
+	fragment := ""
+	if i := strings.IndexByte(v, '#'); i >= 0 {
+		v, fragment = v[:i], v[i:]
+	}
+
+	i := strings.IndexByte(v, '?')
+	if i < 0 {
+		return v + fragment, nil, nil
+	}
+
+	var (
+		base, query = v[:i], v[i+1:]
+		header      http.Header
+		kept        []string
+	)
+
+	for _, param := range strings.Split(query, "&") {
+		kv := strings.SplitN(param, "=", 2)
+		name, err := url.QueryUnescape(kv[0])
+		if err != nil || !strings.HasPrefix(name, HeaderParamPrefix) {
+			kept = append(kept, param)
+			continue
+		}
+
+		value := ""
+		if len(kv) > 1 {
+			if value, err = url.QueryUnescape(kv[1]); err != nil {
+				return "", nil, err
+			}
+		}
+
+		if header == nil {
+			header = make(http.Header)
+		}
+
+		header.Add(name[len(HeaderParamPrefix):], value)
+	}
+
+	if len(kept) > 0 {
+		base += "?" + strings.Join(kept, "&")
+	}
+
+	return base + fragment, header, nil
+}
+
 // HTTPResolver uses an HTTP client to resolve resources.  Resource strings are expected to be
-// valid URIs resolvable by the net/http package.
+// valid URIs resolvable by the net/http package.  The fields of this resolver are copied onto
+// each HTTP handle it produces.
 type HTTPResolver struct {
-	OpenMethod string
-	Client     HTTPClient
+	OpenMethod        string
+	Client            HTTPClient
+	Accept            string
+	ExpectContentType string
+	VerifyLength      bool
+	FollowPagination  bool
+	MaxPages          int
+	MaxBytes          int64
+
+	// ValidateWithHead causes Validate to issue a HEAD request for the resource, failing if the
+	// request is unsuccessful.  By default, Validate only checks that the value is a well-formed URL.
+	ValidateWithHead bool
+
+	// HeaderParams enables declaring request headers within the resource string.  Each query parameter
+	// of the form header.Name=value is removed from the URL and sent as a request header instead, so that
+	// "https://host/x?header.Authorization=Bearer+xyz" sends "Authorization: Bearer xyz".
+	HeaderParams bool
 }
 
 func (r HTTPResolver) Resolve(v string) (Interface, error) {
-	if _, err := url.Parse(v); err != nil {
+	u := v
+	var header http.Header
+	if r.HeaderParams {
+		var err error
+		if u, header, err = extractHeaderParams(v); err != nil {
+			return nil, newResolveError(v, err)
+		}
+	}
+
+	if _, err := url.Parse(u); err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return HTTP{
+		URL:               u,
+		Header:            header,
+		OpenMethod:        r.OpenMethod,
+		Client:            r.Client,
+		Accept:            r.Accept,
+		ExpectContentType: r.ExpectContentType,
+		VerifyLength:      r.VerifyLength,
+		FollowPagination:  r.FollowPagination,
+		MaxPages:          r.MaxPages,
+		MaxBytes:          r.MaxBytes,
+	}, nil
+}
+
+// ResolveOpts is like Resolve, but honors the Timeout, Header, and MaxBytes options
+func (r HTTPResolver) ResolveOpts(v string, opts ...Option) (Interface, error) {
+	h, err := r.Resolve(v)
+	if err != nil {
 		return nil, err
 	}
 
-	return HTTP{URL: v, OpenMethod: r.OpenMethod, Client: r.Client}, nil
+	var (
+		hr = h.(HTTP)
+		o  = NewOptions(opts...)
+	)
+
+	if hr.Client == nil && (len(o.Header) > 0 || o.Timeout > 0) {
+		hr.Client = http.DefaultClient
+	}
+
+	hr.Client = WithHeaders(o.Header, hr.Client)
+	if o.Timeout > 0 {
+		hr.Client = WithTimeout(o.Timeout, hr.Client)
+	}
+
+	if o.MaxBytes > 0 {
+		hr.MaxBytes = o.MaxBytes
+	}
+
+	return hr, nil
+}
+
+// Validate checks that v is a well-formed URL and, if ValidateWithHead is set, that the resource is available
+func (r HTTPResolver) Validate(v string) error {
+	h, err := r.Resolve(v)
+	if err != nil || !r.ValidateWithHead {
+		return err
+	}
+
+	head := h.(HTTP)
+	head.OpenMethod = http.MethodHead
+	response, err := head.response(nil)
+	if err != nil {
+		return err
+	}
+
+	return response.Body.Close()
+}
+
+// UnknownNameError is returned when a NamedResolver has no mapping for a logical name
+type UnknownNameError struct {
+	Value string
+	Name  string
+
+	// Available holds the names that are known, if the resolver reports them
+	Available []string
+}
+
+func (e UnknownNameError) Error() string {
+	if len(e.Available) > 0 {
+		return fmt.Sprintf("Cannot resolve %s: no resource named %s, expected one of [%s]", e.Value, e.Name, strings.Join(e.Available, ", "))
+	}
+
+	return fmt.Sprintf("Cannot resolve %s: no resource named %s", e.Value, e.Name)
+}
+
+// sortedNames returns the keys of a name mapping in sorted order
+func sortedNames(names map[string]string) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Is allows this error to match ErrNotFound
+func (e UnknownNameError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// NamedResolver maps logical names onto resource strings, which are then resolved by another Resolver.
+// This allows code to refer to stable names while configuration controls the actual locations.
+// Any scheme on the value is ignored, so "name://app-config" and "app-config" refer to the same name.
+type NamedResolver struct {
+	// Names is the mapping of logical names onto resource strings
+	Names map[string]string
+
+	// Resolver is the decorated Resolver that receives the mapped resource strings.  This field is required.
+	Resolver Resolver
+}
+
+func (r NamedResolver) Resolve(v string) (Interface, error) {
+	_, name := Split(v)
+	mapped, ok := r.Names[name]
+	if !ok {
+		return nil, UnknownNameError{Value: v, Name: name, Available: sortedNames(r.Names)}
+	}
+
+	return r.Resolver.Resolve(mapped)
 }
 
 // Resolvers represents a mapping of component resolvers by an arbitrary string key.
@@ -107,3 +592,234 @@ func (rs *Resolvers) Set(k string, r Resolver) {
 
 	(*rs)[k] = r
 }
+
+// ContentTypeResolver is a decorator that restricts HTTP resources to an allowed set of media types,
+// guarding against servers that respond with, for example, an HTML error page where JSON was expected.
+// Handles produced by the decorated Resolver are restricted with RestrictContentType, so that opening
+// a resource with any other media type fails with a ContentTypeError.  For HTTP handles, the allowed
+// types are combined with any existing ExpectContentType.  Decorated handles are restricted through the
+// handles they decorate.  Handles whose content type cannot be checked are rejected with an UncheckedContentTypeError.
+type ContentTypeResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Allowed are the permitted media types.  Wildcards such as "application/*" are supported.
+	Allowed []string
+}
+
+func (r ContentTypeResolver) Resolve(v string) (Interface, error) {
+	h, err := r.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.Allowed) == 0 {
+		return h, nil
+	}
+
+	restricted, err := RestrictContentType(h, r.Allowed)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return restricted, nil
+}
+
+// DefaultFallbackSeparator separates the alternatives in a value resolved by a FallbackSchemeResolver
+const DefaultFallbackSeparator = "||"
+
+// FallbackSchemeResolver allows a single value to list alternatives to try in order, such as
+// "file://./local.json||https://cdn/default.json".  Each alternative is passed to Resolver in turn,
+// and the first to succeed is returned.  If every alternative fails, the errors are returned together
+// as a MultiError.
+type FallbackSchemeResolver struct {
+	// Resolver resolves each alternative.  This field is required.
+	Resolver Resolver
+
+	// Separator separates alternatives.  If not supplied, DefaultFallbackSeparator is used.
+	Separator string
+
+	// RequireOpen changes what counts as success.  By default, an alternative succeeds if it resolves.
+	// When this field is set, an alternative succeeds only if its handle can also be opened.  This catches
+	// failures, such as missing files, that only surface on Open, at the cost of opening the chosen
+	// resource an extra time.
+	RequireOpen bool
+}
+
+func (r FallbackSchemeResolver) try(v string) (Interface, error) {
+	h, err := r.Resolver.Resolve(v)
+	if err != nil || !r.RequireOpen {
+		return h, err
+	}
+
+	rc, err := h.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	rc.Close()
+	return h, nil
+}
+
+func (r FallbackSchemeResolver) Resolve(v string) (Interface, error) {
+	separator := r.Separator
+	if len(separator) == 0 {
+		separator = DefaultFallbackSeparator
+	}
+
+	var me MultiError
+	for _, alternative := range strings.Split(v, separator) {
+		h, err := r.try(alternative)
+		if err == nil {
+			return h, nil
+		}
+
+		me = append(me, err)
+	}
+
+	return nil, me
+}
+
+// CanonicalizeResolver is a decorator that normalizes each value before it is resolved, so that the
+// decorated Resolver, and anything such as a cache that it wraps, only ever sees canonical values.
+type CanonicalizeResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Canonicalize transforms each value, e.g. strings.ToLower.  If not supplied, strings.TrimSpace is used.
+	Canonicalize func(string) string
+}
+
+func (r CanonicalizeResolver) Resolve(v string) (Interface, error) {
+	canonicalize := r.Canonicalize
+	if canonicalize == nil {
+		canonicalize = strings.TrimSpace
+	}
+
+	return r.Resolver.Resolve(canonicalize(v))
+}
+
+// OverrideOrDefault produces a Resolver that prefers resources from override, and falls back to a default
+// resource when override has none.  This suits libraries that ship a default resource, such as an embedded
+// file, while allowing users to supply their own.  If override implements Validator, each value is validated
+// first, so that a missing file is detected at resolution time.  When the result satisfies errors.Is(err,
+// ErrNotFound), fallback is returned.  Any other error is returned as is.
+func OverrideOrDefault(override Resolver, fallback Interface) Resolver {
+	return ResolverFunc(func(v string) (Interface, error) {
+		r, err := resolveValidated(override, v)
+		if errors.Is(err, ErrNotFound) {
+			return fallback, nil
+		}
+
+		return r, err
+	})
+}
+
+// CanonicalFileResolver is a decorator that rewrites File handles to refer to the canonical path of the file,
+// with symbolic links evaluated and any "." or ".." segments removed.  Values that reach the same file through
+// different paths thus produce identical handles.  Paths that cannot be evaluated, e.g. because they do not exist,
+// are made absolute and cleaned instead.  Handles other than File are returned as is.
+type CanonicalFileResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+}
+
+// canonicalPath produces the canonical form of a file path
+func canonicalPath(p string) (string, error) {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	if evaluated, err := filepath.EvalSymlinks(p); err == nil {
+		return evaluated, nil
+	}
+
+	return p, nil
+}
+
+func (r CanonicalFileResolver) Resolve(v string) (Interface, error) {
+	h, err := r.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := h.(File)
+	if !ok {
+		return h, nil
+	}
+
+	p, err := canonicalPath(string(f))
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return File(p), nil
+}
+
+// NamespaceResolver is a decorator that places every value under a prefix before it is resolved, so that several
+// components can share one Resolver without their resource strings colliding.  The prefix is joined to the path
+// portion of a value, and the rest of the value is left alone.
+//
+// HTTP and HTTPS values, including compound ones such as "gzip+https://host/config.json", are parsed as URLs, and
+// the prefix is prepended to the URL path.  With a Prefix of "mylib", that value becomes
+// "gzip+https://host/mylib/config.json", with the same host, query, and fragment.
+//
+// Any other value is treated as a path following its scheme, if it has one.  Relative paths are placed under the
+// prefix, so "config.json" and "file://config.json" refer to mylib/config.json.  Absolute paths stay absolute,
+// so "/etc/app.json" becomes "/mylib/etc/app.json" and "file:///etc/app.json" becomes "file:///mylib/etc/app.json".
+//
+// Leading and trailing slashes in the prefix are normalized, so exactly one slash separates the prefix from
+// the path.
+type NamespaceResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Prefix is the namespace prepended to each value's path
+	Prefix string
+}
+
+// namespacePath joins prefix to p.  If p is absolute, so is the result.
+func namespacePath(prefix, p string) string {
+	rest := strings.TrimLeft(p, "/")
+	if len(rest) > 0 {
+		prefix += "/" + rest
+	}
+
+	if len(rest) < len(p) {
+		return "/" + prefix
+	}
+
+	return prefix
+}
+
+func (r NamespaceResolver) Resolve(v string) (Interface, error) {
+	prefix := strings.Trim(r.Prefix, "/")
+	if len(prefix) == 0 {
+		return r.Resolver.Resolve(v)
+	}
+
+	scheme, value := Split(v)
+	components := strings.Split(scheme, CompoundSchemeSeparator)
+	if base := strings.ToLower(components[len(components)-1]); base == HTTPScheme || base == HTTPSScheme {
+		u, err := url.Parse(v)
+		if err != nil {
+			return nil, newResolveError(v, err)
+		}
+
+		// URL paths are always absolute, even when empty
+		u.Path = namespacePath(prefix, "/"+u.Path)
+		if len(u.RawPath) > 0 {
+			u.RawPath = namespacePath(prefix, "/"+u.RawPath)
+		}
+
+		return r.Resolver.Resolve(u.String())
+	}
+
+	namespaced := namespacePath(prefix, value)
+	if len(scheme) > 0 {
+		namespaced = scheme + SchemeSeparator + namespaced
+	}
+
+	return r.Resolver.Resolve(namespaced)
+}
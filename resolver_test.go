@@ -0,0 +1,346 @@
+package resource
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemeErrorsAsResolveError(t *testing.T) {
+	testData := []struct {
+		value  string
+		scheme string
+	}{
+		{"nosuch://resource", "nosuch"},
+		{"resource", ""},
+	}
+
+	for _, record := range testData {
+		t.Run(record.value, func(t *testing.T) {
+			_, err := SchemeResolver{}.Resolve(record.value)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			var re ResolveError
+			if !errors.As(err, &re) {
+				t.Fatalf("Expected %T to be usable as a ResolveError", err)
+			}
+
+			if re.Value != record.value || re.Scheme != record.scheme {
+				t.Errorf("Unexpected ResolveError: %#v", re)
+			}
+
+			if re.Err == nil || re.Err.Error() != err.Error() {
+				t.Errorf("Expected the ResolveError to carry the original error, got %v", re.Err)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorAsResolveError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/missing" {
+			response.WriteHeader(http.StatusNotFound)
+		} else {
+			response.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	defer server.Close()
+
+	t.Run("ServerError", func(t *testing.T) {
+		_, err := HTTP{URL: server.URL + "/broken"}.Open()
+
+		var re ResolveError
+		if !errors.As(err, &re) {
+			t.Fatalf("Expected %v to be usable as a ResolveError", err)
+		}
+
+		var he HTTPError
+		if re.Value != server.URL+"/broken" || re.Scheme != HTTPScheme || !errors.As(re, &he) {
+			t.Errorf("Unexpected ResolveError: %#v", re)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := HTTP{URL: server.URL + "/missing"}.Open()
+
+		var re ResolveError
+		if !errors.As(err, &re) {
+			t.Fatalf("Expected %v to be usable as a ResolveError", err)
+		}
+
+		if !errors.Is(re, ErrNotFound) {
+			t.Errorf("Expected the ResolveError to match ErrNotFound: %#v", re)
+		}
+	})
+}
+
+func TestDiskCacheResolverWrapsLoadErrors(t *testing.T) {
+	cause := errors.New("expected")
+	dcr := DiskCacheResolver{
+		Resolver: ResolverFunc(func(string) (Interface, error) {
+			return nil, cause
+		}),
+		Dir: t.TempDir(),
+	}
+
+	_, err := dcr.Resolve("http://example.com/config")
+
+	var re ResolveError
+	if !errors.As(err, &re) {
+		t.Fatalf("Expected a ResolveError, got %v", err)
+	}
+
+	if re.Value != "http://example.com/config" || !errors.Is(err, cause) {
+		t.Errorf("Unexpected ResolveError: %#v", re)
+	}
+}
+
+func TestFileResolverPercentDecoding(t *testing.T) {
+	root := t.TempDir()
+	testData := []struct {
+		value string
+		path  string
+	}{
+		{"file:///my%20dir/config%20file.json", filepath.Join("my dir", "config file.json")},
+		{"file:///caf%C3%A9/%E6%97%A5%E6%9C%AC.json", filepath.Join("café", "日本.json")},
+		{"file:///café/日本.json", filepath.Join("café", "日本.json")},
+		{"100%25.json", "100%25.json"},
+		{"my%20dir/x.json", "my%20dir/x.json"},
+	}
+
+	for _, record := range testData {
+		t.Run(record.value, func(t *testing.T) {
+			expected := filepath.Join(root, record.path)
+			if err := os.MkdirAll(filepath.Dir(expected), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ioutil.WriteFile(expected, []byte(record.value), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := FileResolver{Root: root}.Resolve(record.value)
+			if err != nil {
+				t.Fatalf("Resolve failed: %s", err)
+			}
+
+			if actual, ok := r.(File); !ok || string(actual) != expected {
+				t.Fatalf("Expected File(%q), got %#v", expected, r)
+			}
+
+			if actual := readAll(t, r); actual != record.value {
+				t.Errorf("Expected content %q, got %q", record.value, actual)
+			}
+		})
+	}
+}
+
+func TestFileResolverMalformedEscape(t *testing.T) {
+	_, err := FileResolver{Root: t.TempDir()}.Resolve("file:///bad%zz.json")
+
+	var re ResolveError
+	if !errors.As(err, &re) {
+		t.Errorf("Expected a ResolveError, got %v", err)
+	}
+}
+
+// opaqueDecorator decorates a handle without embedding it, so it cannot be rewrapped
+type opaqueDecorator struct {
+	decorated Interface
+}
+
+func (od opaqueDecorator) Location() string                   { return od.decorated.Location() }
+func (od opaqueDecorator) Open() (io.ReadCloser, error)       { return od.decorated.Open() }
+func (od opaqueDecorator) WriteTo(w io.Writer) (int64, error) { return od.decorated.WriteTo(w) }
+func (od opaqueDecorator) Unwrap() Interface                  { return od.decorated }
+
+func TestContentTypeResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/json":
+			response.Header().Set("Content-Type", "application/json; charset=utf-8")
+			response.Write([]byte(`{}`))
+
+		case "/xml":
+			response.Header().Set("Content-Type", "application/xml")
+			response.Write([]byte(`<xml/>`))
+
+		default:
+			response.Header().Set("Content-Type", "text/html")
+			response.Write([]byte(`<html></html>`))
+		}
+	}))
+
+	defer server.Close()
+
+	testData := []struct {
+		name       string
+		handle     Interface
+		allowed    []string
+		resolveErr interface{}
+		openErr    bool
+	}{
+		{"Allowed", HTTP{URL: server.URL + "/json"}, []string{"application/*"}, nil, false},
+		{"NotAllowed", HTTP{URL: server.URL + "/html"}, []string{"application/*"}, nil, true},
+		{"Intersected", HTTP{URL: server.URL + "/xml", ExpectContentType: "application/json, text/html"}, []string{"application/*"}, nil, true},
+		{"Disjoint", HTTP{URL: server.URL + "/json", ExpectContentType: "text/html"}, []string{"application/json"}, new(ContentTypeError), false},
+		{"DecoratedAllowed", Retrying{Interface: HTTP{URL: server.URL + "/json"}}, []string{"application/json"}, nil, false},
+		{"DecoratedNotAllowed", Retrying{Interface: HTTP{URL: server.URL + "/html"}}, []string{"application/json"}, nil, true},
+		{"Unchecked", String("content"), []string{"application/json"}, new(UncheckedContentTypeError), false},
+		{"NestedDecorators", GzipWriteTo(WithReadTimeout(time.Second, HTTP{URL: server.URL + "/html"}), gzip.DefaultCompression), []string{"application/json"}, nil, true},
+		{"ParallelRanges", WithParallelRanges(2, HTTP{URL: server.URL + "/html"}), []string{"application/json"}, nil, true},
+		{"UncheckedDecorated", opaqueDecorator{HTTP{URL: server.URL + "/json"}}, []string{"application/json"}, new(UncheckedContentTypeError), false},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			ctr := ContentTypeResolver{
+				Resolver: ResolverFunc(func(string) (Interface, error) { return record.handle, nil }),
+				Allowed:  record.allowed,
+			}
+
+			r, err := ctr.Resolve("value")
+			if record.resolveErr != nil {
+				if !errors.As(err, record.resolveErr) {
+					t.Errorf("Expected a %T, got %v", record.resolveErr, err)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("Resolve failed: %s", err)
+			}
+
+			// some decorators, such as GzipWriteTo, only report errors from the decorated handle as they are read
+			rc, err := r.Open()
+			if err == nil {
+				_, err = ioutil.ReadAll(rc)
+				rc.Close()
+			}
+
+			var cte ContentTypeError
+			if record.openErr && !errors.As(err, &cte) {
+				t.Errorf("Expected a ContentTypeError, got %v", err)
+			} else if !record.openErr && err != nil {
+				t.Errorf("Open failed: %s", err)
+			}
+		})
+	}
+}
+
+func TestIntersectMediaRanges(t *testing.T) {
+	testData := []struct {
+		a, b     []string
+		expected string
+	}{
+		{[]string{"application/*"}, []string{"application/json", "text/plain"}, "application/json"},
+		{[]string{"application/json", " text/html"}, []string{"application/*"}, "application/json"},
+		{[]string{"*/*"}, []string{"text/*"}, "text/*"},
+		{[]string{"text/plain"}, []string{"application/json"}, ""},
+	}
+
+	for _, record := range testData {
+		if actual := strings.Join(intersectMediaRanges(record.a, record.b), ", "); actual != record.expected {
+			t.Errorf("Expected the intersection of %q and %q to be %q, got %q", record.a, record.b, record.expected, actual)
+		}
+	}
+}
+
+func TestRandomResolverMaxSize(t *testing.T) {
+	testData := []struct {
+		name     string
+		resolver RandomResolver
+		value    string
+		valid    bool
+	}{
+		{"Empty", RandomResolver{}, "random://0", true},
+		{"Small", RandomResolver{}, "random://32", true},
+		{"DefaultMax", RandomResolver{}, "random://" + strconv.Itoa(DefaultRandomMaxSize), true},
+		{"OverDefaultMax", RandomResolver{}, "random://" + strconv.Itoa(DefaultRandomMaxSize+1), false},
+		{"Huge", RandomResolver{}, "random://9999999999999", false},
+		{"CustomMax", RandomResolver{MaxSize: 16}, "random://16", true},
+		{"OverCustomMax", RandomResolver{MaxSize: 16}, "random://17", false},
+		{"Negative", RandomResolver{}, "random://-1", false},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			r, err := record.resolver.Resolve(record.value)
+			if record.valid {
+				if err != nil {
+					t.Fatalf("Resolve failed: %s", err)
+				}
+
+				if s := r.(Sized).Size(); strconv.FormatInt(s, 10) != record.value[len("random://"):] {
+					t.Errorf("Unexpected size %d for %s", s, record.value)
+				}
+
+				return
+			}
+
+			var re ResolveError
+			if !errors.As(err, &re) || re.Value != record.value {
+				t.Errorf("Expected a ResolveError for %s, got %v", record.value, err)
+			}
+
+			if verr := record.resolver.Validate(record.value); verr == nil {
+				t.Errorf("Expected Validate to reject %s", record.value)
+			}
+		})
+	}
+}
+
+func TestNamespaceResolver(t *testing.T) {
+	testData := []struct {
+		name     string
+		prefix   string
+		value    string
+		expected string
+	}{
+		{"URL", "mylib", "https://host/config.json", "https://host/mylib/config.json"},
+		{"URLQueryAndFragment", "mylib", "http://host:8080/a/b.json?x=1#top", "http://host:8080/mylib/a/b.json?x=1#top"},
+		{"URLWithoutPath", "mylib", "https://host", "https://host/mylib"},
+		{"URLEscapedPath", "mylib", "https://host/a%2Fb.json", "https://host/mylib/a%2Fb.json"},
+		{"CompoundURL", "mylib", "gzip+https://host/config.json", "gzip+https://host/mylib/config.json"},
+		{"AbsolutePath", "mylib", "/etc/app.json", "/mylib/etc/app.json"},
+		{"AbsoluteFile", "mylib", "file:///etc/app.json", "file:///mylib/etc/app.json"},
+		{"RelativePath", "mylib", "config/app.json", "mylib/config/app.json"},
+		{"RelativeFile", "mylib", "file://config.json", "file://mylib/config.json"},
+		{"SchemeLess", "mylib", "config.json", "mylib/config.json"},
+		{"NormalizedPrefix", "/mylib/", "config.json", "mylib/config.json"},
+		{"NestedPrefix", "a/b", "/etc/app.json", "/a/b/etc/app.json"},
+		{"EmptyPrefix", "", "https://host/config.json", "https://host/config.json"},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			var actual string
+			r := NamespaceResolver{
+				Resolver: ResolverFunc(func(v string) (Interface, error) {
+					actual = v
+					return String(v), nil
+				}),
+				Prefix: record.prefix,
+			}
+
+			if _, err := r.Resolve(record.value); err != nil {
+				t.Fatalf("Resolve failed: %s", err)
+			}
+
+			if actual != record.expected {
+				t.Errorf("Expected %s to be namespaced as %s, got %s", record.value, record.expected, actual)
+			}
+		})
+	}
+}
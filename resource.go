@@ -2,11 +2,17 @@ package resource
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/textproto"
 	"os"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Interface represents a handle to a resource.
@@ -21,6 +27,102 @@ type Interface interface {
 	Open() (io.ReadCloser, error)
 }
 
+// ContextOpener is an optional interface implemented by resource handles whose Open can be cancelled
+type ContextOpener interface {
+	// OpenContext is like Open, but abandons the open and returns ctx.Err() if ctx is done first
+	OpenContext(ctx context.Context) (io.ReadCloser, error)
+}
+
+// OpenContext opens a resource, using OpenContext if r implements ContextOpener.  Otherwise, ctx is only
+// checked before r.Open is called, since an ordinary Open cannot be interrupted.
+func OpenContext(ctx context.Context, r Interface) (io.ReadCloser, error) {
+	if co, ok := r.(ContextOpener); ok {
+		return co.OpenContext(ctx)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.Open()
+}
+
+// Rereadable is an optional interface implemented by resource handles that can report whether reading
+// them again is cheap and produces the same content, as opposed to one-shot or remote sources.
+type Rereadable interface {
+	Rereadable() bool
+}
+
+// ContentTyped is an optional interface implemented by resource handles whose content can be restricted
+// to certain media types.  Decorators need not implement this interface, since RestrictContentType
+// restricts the handle they decorate instead.
+type ContentTyped interface {
+	// RestrictContentType returns a handle like this one, except that opening it fails with a ContentTypeError
+	// unless the content's media type matches one of the allowed media ranges.  Any restriction already in
+	// place continues to apply, so the result only permits media types that satisfy both.
+	RestrictContentType(allowed []string) (Interface, error)
+}
+
+// RestrictContentType restricts the media types of r's content.  If r is a decorator, i.e. it implements
+// Unwrapper, the handle it decorates is restricted and r is rewrapped around the result.  Otherwise, r's own
+// RestrictContentType is used if r implements ContentTyped.  If r's content type cannot be checked, including
+// when r is a decorator that cannot be rewrapped, an UncheckedContentTypeError is returned.
+func RestrictContentType(r Interface, allowed []string) (Interface, error) {
+	if u, ok := r.(Unwrapper); ok {
+		restricted, err := RestrictContentType(u.Unwrap(), allowed)
+		if err != nil {
+			return nil, err
+		}
+
+		if rewrapped, ok := rewrap(r, restricted); ok {
+			return rewrapped, nil
+		}
+	} else if ct, ok := r.(ContentTyped); ok {
+		return ct.RestrictContentType(allowed)
+	}
+
+	return nil, UncheckedContentTypeError{Location: r.Location()}
+}
+
+// rewrap returns a copy of the decorator d that decorates r instead.  This works for decorators, such as
+// those in this package, that are structs embedding the handle they decorate.  The first exported
+// embedded field to which r can be assigned is replaced.  For any other kind of decorator, including
+// pointers, rewrap returns false.
+func rewrap(d, r Interface) (Interface, bool) {
+	dv := reflect.ValueOf(d)
+	if dv.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	var (
+		copied = reflect.New(dv.Type()).Elem()
+		rv     = reflect.ValueOf(r)
+	)
+
+	copied.Set(dv)
+	for i := 0; i < dv.NumField(); i++ {
+		if field := dv.Type().Field(i); field.Anonymous && len(field.PkgPath) == 0 && rv.Type().AssignableTo(field.Type) {
+			copied.Field(i).Set(rv)
+			return copied.Interface().(Interface), true
+		}
+	}
+
+	return nil, false
+}
+
+// Sized is an optional interface implemented by resource handles that know the size of their content
+// without reading it
+type Sized interface {
+	// Size returns the number of bytes of content, or -1 if the size is not known
+	Size() int64
+}
+
+// IsRereadable tests whether r implements Rereadable and reports that it can be reread
+func IsRereadable(r Interface) bool {
+	rr, ok := r.(Rereadable)
+	return ok && rr.Rereadable()
+}
+
 // String represents an in-memory resource backed by a golang string.
 type String string
 
@@ -28,13 +130,35 @@ func (s String) Location() string {
 	return "string"
 }
 
+// Rereadable always returns true, since a string can be read any number of times
+func (s String) Rereadable() bool {
+	return true
+}
+
+// Size returns the length of the string
+func (s String) Size() int64 {
+	return int64(len(s))
+}
+
 func (s String) Open() (io.ReadCloser, error) {
 	return ioutil.NopCloser(strings.NewReader(string(s))), nil
 }
 
+// WriteTo writes the entire string to w.  A writer that accepts only part of the string without an error
+// is written to again with the remainder.  If a write makes no progress at all, io.ErrShortWrite is returned.
 func (s String) WriteTo(w io.Writer) (int64, error) {
-	count, err := io.WriteString(w, string(s))
-	return int64(count), err
+	var total int
+	for total < len(s) {
+		count, err := io.WriteString(w, string(s[total:]))
+		total += count
+		if err != nil {
+			return int64(total), err
+		} else if count == 0 {
+			return int64(total), io.ErrShortWrite
+		}
+	}
+
+	return int64(total), nil
 }
 
 // Bytes represents an in-memory resource backed by a byte slice.
@@ -44,13 +168,66 @@ func (b Bytes) Location() string {
 	return "bytes"
 }
 
+// Rereadable always returns true, since a byte slice can be read any number of times
+func (b Bytes) Rereadable() bool {
+	return true
+}
+
+// Size returns the length of the byte slice
+func (b Bytes) Size() int64 {
+	return int64(len(b))
+}
+
 func (b Bytes) Open() (io.ReadCloser, error) {
 	return ioutil.NopCloser(bytes.NewReader([]byte(b))), nil
 }
 
+// WriteTo writes the entire byte slice to w, in the same way as String.WriteTo
 func (b Bytes) WriteTo(w io.Writer) (int64, error) {
-	count, err := w.Write([]byte(b))
-	return int64(count), err
+	var total int
+	for total < len(b) {
+		count, err := w.Write([]byte(b[total:]))
+		total += count
+		if err != nil {
+			return int64(total), err
+		} else if count == 0 {
+			return int64(total), io.ErrShortWrite
+		}
+	}
+
+	return int64(total), nil
+}
+
+// Func represents a resource whose content is computed each time it is opened.
+type Func struct {
+	// Name is the optional location reported by this resource.  If not supplied, "func" is used.
+	Name string
+
+	// Source is the required function that produces this resource's content.  It is invoked
+	// for each Open or WriteTo.
+	Source func() (io.ReadCloser, error)
+}
+
+func (f Func) Location() string {
+	if len(f.Name) > 0 {
+		return f.Name
+	}
+
+	return "func"
+}
+
+func (f Func) Open() (io.ReadCloser, error) {
+	return f.Source()
+}
+
+func (f Func) WriteTo(w io.Writer) (int64, error) {
+	rc, err := f.Source()
+	if err != nil {
+		return int64(0), err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
 }
 
 // File represents a resource backed by a system file.
@@ -60,22 +237,159 @@ func (f File) Location() string {
 	return string(f)
 }
 
+// Rereadable always returns true, since a file is simply reopened.  Files such as named pipes, which
+// cannot be reread, should not be represented by File.
+func (f File) Rereadable() bool {
+	return true
+}
+
 func (f File) Open() (io.ReadCloser, error) {
 	return os.Open(string(f))
 }
 
+// openContext opens this file in a separate goroutine, so that a blocked open can be abandoned
+func (f File) openContext(ctx context.Context) (*os.File, error) {
+	type openResult struct {
+		file *os.File
+		err  error
+	}
+
+	result := make(chan openResult)
+	go func() {
+		file, err := os.Open(string(f))
+		select {
+		case result <- openResult{file, err}:
+		case <-ctx.Done():
+			if file != nil {
+				file.Close()
+			}
+		}
+	}()
+
+	select {
+	case r := <-result:
+		return r.file, r.err
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// OpenContext is like Open, but returns ctx.Err() if ctx is done before the file is opened.  This guards
+// against opens that block indefinitely, such as on a hung network filesystem.
+//
+// When an open is abandoned, the goroutine performing it remains blocked until the open completes,
+// at which point the file is closed.
+func (f File) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	file, err := f.openContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// OpenWithTimeout is like Open, but guards against files such as named pipes that can block.  If the
+// file cannot be opened within the given duration, an OpenTimeoutError is returned.  Additionally, each
+// Read from the returned io.ReadCloser fails with a ReadTimeoutError if it blocks for longer than d.
+//
+// When an open times out, the goroutine performing it remains blocked until the open completes,
+// at which point the file is closed.
+func (f File) OpenWithTimeout(d time.Duration) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	file, err := f.openContext(ctx)
+	if err == context.DeadlineExceeded {
+		return nil, OpenTimeoutError{Path: string(f), Duration: d}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &timeoutReader{rc: file, location: f.Location(), d: d}, nil
+}
+
+// OpenRange opens length bytes of this file, beginning at the byte offset start.  The range must lie
+// entirely within the file, otherwise a RangeError is returned.  The file is never read outside the range.
+func (f File) OpenRange(start, length int64) (io.ReadCloser, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if start < 0 || length < 0 || start > fi.Size() || length > fi.Size()-start {
+		file.Close()
+		return nil, RangeError{Path: string(f), Start: start, Length: length, Size: fi.Size()}
+	}
+
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return readCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+// WriteTo copies this file's contents to w.  The *os.File is handed directly to io.Copy, so that
+// destinations such as *net.TCPConn or another *os.File can use sendfile and similar zero-copy paths.
 func (f File) WriteTo(w io.Writer) (int64, error) {
-	rc, err := f.Open()
+	file, err := os.Open(string(f))
 	if err != nil {
 		return int64(0), err
 	}
 
-	defer rc.Close()
-	count, err := io.Copy(w, rc)
-	return int64(count), err
+	defer file.Close()
+	return io.Copy(w, file)
+}
+
+// NotRegularFileError is returned when a path refers to something other than a regular file
+type NotRegularFileError struct {
+	Path string
+	Mode os.FileMode
+}
+
+func (e NotRegularFileError) Error() string {
+	return fmt.Sprintf("%s is not a regular file (mode %s)", e.Path, e.Mode)
+}
+
+// OpenTimeoutError is returned when a file could not be opened within the allowed duration
+type OpenTimeoutError struct {
+	Path     string
+	Duration time.Duration
+}
+
+func (e OpenTimeoutError) Error() string {
+	return fmt.Sprintf("Opening %s timed out after %s", e.Path, e.Duration)
+}
+
+// Timeout always returns true.  This method allows this error to be treated like a net.Error.
+func (e OpenTimeoutError) Timeout() bool {
+	return true
+}
+
+// RangeError is returned when a requested byte range does not lie within a file
+type RangeError struct {
+	Path   string
+	Start  int64
+	Length int64
+	Size   int64
+}
+
+func (e RangeError) Error() string {
+	return fmt.Sprintf("Range [%d, %d) is outside %s, which has %d bytes", e.Start, e.Start+e.Length, e.Path, e.Size)
 }
 
 // HTTP represents a resource backed by an HTTP or HTTPS URL.
+//
+// Responses are always streamed.  In particular, responses that use chunked transfer encoding, and so
+// have no Content-Length, are de-chunked by net/http and copied as they arrive.  None of the optional
+// checks below buffers a response body.
 type HTTP struct {
 	// URL is the required URL of the resource
 	URL string
@@ -87,24 +401,67 @@ type HTTP struct {
 	// Client is the HTTP client to use to obtain the resource.  If not supplied,
 	// http.DefaultClient is used.
 	Client HTTPClient
+
+	// Accept is the optional value of the Accept header sent with each request
+	Accept string
+
+	// Header holds optional headers sent with each request
+	Header http.Header
+
+	// ExpectContentType is the optional media type the response must have, e.g. "application/json".
+	// Several media types may be given, separated by commas, and wildcards such as "application/*" are
+	// permitted.  Any parameters on the response's Content-Type, such as charset, are ignored.  If supplied,
+	// a response with any other media type results in a ContentTypeError.
+	ExpectContentType string
+
+	// VerifyLength enables verification that the number of bytes read from a response body matches
+	// the response's Content-Length.  A mismatch results in a ShortReadError once the body is consumed.
+	// Responses with no Content-Length are not verified.
+	VerifyLength bool
+
+	// FollowPagination enables following RFC 5988 Link headers with rel="next".  When enabled, the bodies
+	// of each page are read in turn as a single stream, so this resource represents the full data set.
+	// Each page is verified in the same way as the first.
+	FollowPagination bool
+
+	// MaxPages caps the number of pages read when FollowPagination is enabled.  Pages beyond this
+	// limit are ignored.  If not supplied, DefaultMaxPages is used.
+	MaxPages int
+
+	// MaxBytes is the optional limit on the size of each response body.  Reading past this limit
+	// fails with a TooLargeError.
+	MaxBytes int64
 }
 
 func (h HTTP) Location() string {
 	return h.URL
 }
 
-// transact performs an HTTP transaction using this resource's configuration
-func (h HTTP) transact() (*http.Response, error) {
+// transact performs an HTTP transaction for a URL using this resource's configuration.
+// The given header, which may be nil, is added to the request.
+func (h HTTP) transact(u string, header http.Header) (*http.Response, error) {
 	method := h.OpenMethod
 	if len(method) == 0 {
 		method = http.MethodGet
 	}
 
-	request, err := http.NewRequest(method, h.URL, nil)
+	request, err := http.NewRequest(method, u, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(h.Accept) > 0 {
+		request.Header.Set("Accept", h.Accept)
+	}
+
+	for k, v := range h.Header {
+		request.Header[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+
+	for k, v := range header {
+		request.Header[k] = v
+	}
+
 	c := h.Client
 	if c == nil {
 		c = http.DefaultClient
@@ -113,23 +470,219 @@ func (h HTTP) transact() (*http.Response, error) {
 	return c.Do(request)
 }
 
-func (h HTTP) Open() (io.ReadCloser, error) {
-	response, err := h.transact()
+// checkContentType verifies the response's media type against ExpectContentType
+func (h HTTP) checkContentType(u string, response *http.Response) error {
+	if len(h.ExpectContentType) == 0 {
+		return nil
+	}
+
+	actual := response.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(actual); err == nil {
+		for _, expected := range strings.Split(h.ExpectContentType, ",") {
+			if matchMediaType(strings.TrimSpace(expected), mediaType) {
+				return nil
+			}
+		}
+	}
+
+	return ContentTypeError{URL: u, Expected: h.ExpectContentType, Actual: actual}
+}
+
+// RestrictContentType returns a copy of this resource whose ExpectContentType permits only the media types
+// allowed both by the given media ranges and by any existing ExpectContentType.  If no media type can satisfy
+// both, a ContentTypeError reporting the existing ExpectContentType as the actual type is returned.
+func (h HTTP) RestrictContentType(allowed []string) (Interface, error) {
+	restricted := allowed
+	if len(h.ExpectContentType) > 0 {
+		restricted = intersectMediaRanges(strings.Split(h.ExpectContentType, ","), allowed)
+		if len(restricted) == 0 {
+			return nil, ContentTypeError{URL: h.URL, Expected: strings.Join(allowed, ", "), Actual: h.ExpectContentType}
+		}
+	}
+
+	h.ExpectContentType = strings.Join(restricted, ", ")
+	return h, nil
+}
+
+// intersectMediaRanges returns the media ranges that match only media types matched by both a and b.
+// For example, the intersection of "application/*" and "application/json, text/plain" is "application/json".
+func intersectMediaRanges(a, b []string) []string {
+	var intersection []string
+	for _, x := range a {
+		x = strings.TrimSpace(x)
+		for _, y := range b {
+			y = strings.TrimSpace(y)
+			switch {
+			case matchMediaType(x, y):
+				intersection = append(intersection, y)
+
+			case matchMediaType(y, x):
+				intersection = append(intersection, x)
+			}
+		}
+	}
+
+	return intersection
+}
+
+// matchMediaType tests whether a media type matches a media range such as "text/plain", "text/*", or "*/*"
+func matchMediaType(mediaRange, mediaType string) bool {
+	switch {
+	case mediaRange == "*/*":
+		return true
+
+	case strings.HasSuffix(mediaRange, "/*"):
+		return len(mediaType) > len(mediaRange)-1 && strings.EqualFold(mediaType[:len(mediaRange)-1], mediaRange[:len(mediaRange)-1])
+
+	default:
+		return strings.EqualFold(mediaType, mediaRange)
+	}
+}
+
+// fetch performs an HTTP transaction for a URL and verifies the result.  If the response is not
+// acceptable, its body is drained and closed and an error is returned.
+func (h HTTP) fetch(u string, header http.Header) (*http.Response, error) {
+	response, err := h.transact(u, header)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.StatusCode < 200 || response.StatusCode > 299 {
+	if response.StatusCode == http.StatusNotModified {
+		err = ErrNotModified
+	} else if response.StatusCode == http.StatusNotFound {
+		err = ResourceNotFoundError{HTTPError{u, response.StatusCode}}
+	} else if response.StatusCode < 200 || response.StatusCode > 299 {
+		err = HTTPError{u, response.StatusCode}
+	} else {
+		err = h.checkContentType(u, response)
+	}
+
+	if err != nil {
 		io.Copy(ioutil.Discard, response.Body)
 		response.Body.Close()
-		return nil, HTTPError{h.URL, response.StatusCode}
+		return nil, err
 	}
 
-	return DrainOnClose(response.Body), nil
+	if h.MaxBytes > 0 {
+		response.Body = &maxBytesReader{ReadCloser: response.Body, location: u, limit: h.MaxBytes}
+	}
+
+	if h.VerifyLength && response.ContentLength >= 0 {
+		response.Body = &lengthVerifier{
+			ReadCloser: response.Body,
+			url:        u,
+			expected:   response.ContentLength,
+		}
+	}
+
+	return response, nil
+}
+
+// response fetches this resource, following pagination if configured
+func (h HTTP) response(header http.Header) (*http.Response, error) {
+	response, err := h.fetch(h.URL, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.FollowPagination {
+		response.Body = &pageReader{h: h, current: response, body: response.Body, pages: 1}
+	}
+
+	return response, nil
+}
+
+// lengthVerifier verifies that a response body's length matches the expected Content-Length
+type lengthVerifier struct {
+	io.ReadCloser
+	url      string
+	expected int64
+	actual   int64
+}
+
+func (lv *lengthVerifier) Read(p []byte) (int, error) {
+	n, err := lv.ReadCloser.Read(p)
+	lv.actual += int64(n)
+	if (err == io.EOF || err == io.ErrUnexpectedEOF) && lv.actual != lv.expected {
+		err = ShortReadError{URL: lv.url, Expected: lv.expected, Actual: lv.actual}
+	}
+
+	return n, err
+}
+
+// drainable ensures that closing a response body drains it.  Paginated bodies already drain their
+// current page when closed, and draining them here would fetch every remaining page.
+func drainable(body io.ReadCloser) io.ReadCloser {
+	if _, ok := body.(*pageReader); ok {
+		return body
+	}
+
+	return DrainOnClose(body)
+}
+
+func (h HTTP) Open() (io.ReadCloser, error) {
+	response, err := h.response(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return drainable(response.Body), nil
+}
+
+// ConditionalReader is the io.ReadCloser returned by HTTP's conditional open methods.  It exposes the
+// cache validators from the response so that callers can make further conditional requests.
+type ConditionalReader struct {
+	io.ReadCloser
+	etag         string
+	lastModified time.Time
+}
+
+// ETag returns the response's entity tag, or the empty string if the response had none
+func (cr *ConditionalReader) ETag() string {
+	return cr.etag
+}
+
+// LastModified returns the response's Last-Modified time, or the zero time if the response had none
+func (cr *ConditionalReader) LastModified() time.Time {
+	return cr.lastModified
+}
+
+func (h HTTP) openConditional(name, value string) (*ConditionalReader, error) {
+	response, err := h.response(http.Header{name: {value}})
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &ConditionalReader{
+		ReadCloser: drainable(response.Body),
+		etag:       response.Header.Get("ETag"),
+	}
+
+	if lm, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+		cr.lastModified = lm
+	}
+
+	return cr, nil
+}
+
+// OpenIfModifiedSince is like Open, but makes the request conditional on the resource having changed since
+// the given time.  If the server responds with 304 Not Modified, ErrNotModified is returned.
+func (h HTTP) OpenIfModifiedSince(t time.Time) (*ConditionalReader, error) {
+	return h.openConditional("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// OpenIfNoneMatch is like Open, but makes the request conditional on the resource's entity tag differing
+// from the given one.  If the server responds with 304 Not Modified, ErrNotModified is returned.
+func (h HTTP) OpenIfNoneMatch(etag string) (*ConditionalReader, error) {
+	return h.openConditional("If-None-Match", etag)
 }
 
+// WriteTo copies the response body to w as it is received, whether or not the response has a Content-Length.
+// The response is verified exactly as Open verifies it:  a non-2xx status produces an HTTPError, 304 Not Modified
+// produces ErrNotModified, and ExpectContentType, MaxBytes, VerifyLength, and FollowPagination all apply.  Nothing
+// is written to w when the response is rejected.
 func (h HTTP) WriteTo(w io.Writer) (int64, error) {
-	response, err := h.transact()
+	response, err := h.response(nil)
 	if err != nil {
 		return int64(0), err
 	}
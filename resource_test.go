@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readerFromRecorder captures the reader that io.Copy hands to ReadFrom.  Destinations such as
+// *os.File and *net.TCPConn choose their zero-copy paths based on this reader.
+type readerFromRecorder struct {
+	bytes.Buffer
+	source io.Reader
+}
+
+func (rfr *readerFromRecorder) ReadFrom(r io.Reader) (int64, error) {
+	rfr.source = r
+	return rfr.Buffer.ReadFrom(r)
+}
+
+func writeTempFile(tb testing.TB, content []byte) string {
+	path := filepath.Join(tb.TempDir(), "resource")
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		tb.Fatalf("Unable to write %s: %s", path, err)
+	}
+
+	return path
+}
+
+// readAll opens a resource and returns its entire content, failing the test on any error
+func readAll(tb testing.TB, r Interface) string {
+	rc, err := r.Open()
+	if err != nil {
+		tb.Fatalf("Open failed: %s", err)
+	}
+
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		tb.Fatalf("ReadAll failed: %s", err)
+	}
+
+	return string(data)
+}
+
+func TestFileWriteToHandsOffOSFile(t *testing.T) {
+	path := writeTempFile(t, []byte("file content"))
+
+	var dst readerFromRecorder
+	n, err := File(path).WriteTo(&dst)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+
+	if n != int64(len("file content")) || dst.String() != "file content" {
+		t.Errorf("Unexpected content: %d bytes, %q", n, dst.String())
+	}
+
+	// depending on the Go version, ReadFrom receives either the *os.File or a thin wrapper around it.
+	// Either way, the file descriptor must be reachable so that sendfile and splice can be used.
+	if _, ok := dst.source.(interface{ Fd() uintptr }); !ok {
+		t.Errorf("Expected ReadFrom to receive a file-backed reader, got %T", dst.source)
+	}
+}
+
+func BenchmarkFileWriteToFile(b *testing.B) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	source := File(writeTempFile(b, content))
+
+	dst, err := os.Create(filepath.Join(b.TempDir(), "destination"))
+	if err != nil {
+		b.Fatalf("Unable to create destination: %s", err)
+	}
+
+	defer dst.Close()
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := dst.Seek(0, io.SeekStart); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := source.WriteTo(dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// shortWriter accepts at most max bytes per Write, without reporting an error.  The buffer is not
+// embedded, since its WriteString method would otherwise bypass the limit.
+type shortWriter struct {
+	buffer bytes.Buffer
+	max    int
+}
+
+func (sw *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > sw.max {
+		p = p[:sw.max]
+	}
+
+	return sw.buffer.Write(p)
+}
+
+func TestInMemoryWriteToShortWrites(t *testing.T) {
+	const content = "a string long enough to need several writes"
+	for _, r := range []Interface{String(content), Bytes(content)} {
+		t.Run(fmt.Sprintf("%T", r), func(t *testing.T) {
+			sw := &shortWriter{max: 5}
+			n, err := r.WriteTo(sw)
+			if err != nil {
+				t.Fatalf("WriteTo failed: %s", err)
+			}
+
+			if n != int64(len(content)) || sw.buffer.String() != content {
+				t.Errorf("Expected %q, got %d bytes: %q", content, n, sw.buffer.String())
+			}
+		})
+
+		t.Run(fmt.Sprintf("%TNoProgress", r), func(t *testing.T) {
+			sw := &shortWriter{max: 0}
+			if n, err := r.WriteTo(sw); err != io.ErrShortWrite || n != 0 {
+				t.Errorf("Expected io.ErrShortWrite after 0 bytes, got %d bytes and %v", n, err)
+			}
+		})
+	}
+}
@@ -1,18 +1,27 @@
 package resource
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
 const (
 	SchemeSeparator = "://"
 
-	StringScheme = "string"
-	BytesScheme  = "bytes"
-	FileScheme   = "file"
-	HTTPScheme   = "http"
-	HTTPSScheme  = "https"
+	StringScheme    = "string"
+	LiteralScheme   = "literal"
+	BytesScheme     = "bytes"
+	Base64URLScheme = "b64url"
+	Base32Scheme    = "base32"
+	FileScheme      = "file"
+	HTTPScheme      = "http"
+	HTTPSScheme     = "https"
+	NameScheme      = "name"
+	RandomScheme    = "random"
+	UUIDScheme      = "uuid"
 )
 
 // Split parses a resource value into its scheme and value.
@@ -31,9 +40,14 @@ func Split(v string) (scheme, value string) {
 // These mappings are:
 //
 //   StringScheme is mapped to a StringResolver
+//   LiteralScheme is mapped to a StringResolver, and is exempt from template expansion
 //   BytesScheme is mapped to a BytesResolver with standard base64 encoding
+//   Base64URLScheme is mapped to a Base64URLResolver
+//   Base32Scheme is mapped to a Base32Resolver with standard base32 encoding
 //   FileScheme is mapped to a FileResolver with no relative path
 //   HTTPScheme and HTTPSScheme are mapped to an HTTPResolver using the default HTTP Client
+//   RandomScheme is mapped to a RandomResolver
+//   UUIDScheme is mapped to a UUIDResolver
 //
 // When constructing custom SchemeResolver instances, this function is useful as a starting point.
 func NewDefaultSchemeResolvers() Resolvers {
@@ -43,14 +57,107 @@ func NewDefaultSchemeResolvers() Resolvers {
 	)
 
 	return Resolvers{
-		StringScheme: StringResolver{},
-		BytesScheme:  BytesResolver{},
-		FileScheme:   fr,
-		HTTPScheme:   hr,
-		HTTPSScheme:  hr,
+		StringScheme:    StringResolver{},
+		LiteralScheme:   StringResolver{},
+		BytesScheme:     BytesResolver{},
+		Base64URLScheme: Base64URLResolver{},
+		Base32Scheme:    Base32Resolver{},
+		FileScheme:      fr,
+		HTTPScheme:      hr,
+		HTTPSScheme:     hr,
+		RandomScheme:    RandomResolver{},
+		UUIDScheme:      UUIDResolver{},
 	}
 }
 
+// Base64 encoding names recognized by SchemeResolverConfig
+const (
+	StdEncoding    = "std"
+	URLEncoding    = "url"
+	RawStdEncoding = "rawstd"
+	RawURLEncoding = "rawurl"
+)
+
+// SchemeResolverConfig describes the options used to build a SchemeResolver with the default scheme
+// mappings.  All fields are optional.  This type is suitable for unmarshaling from configuration files.
+type SchemeResolverConfig struct {
+	// FileRoot is the Root used for the FileScheme resolver as well as for values with no scheme
+	FileRoot string `json:"fileRoot,omitempty" yaml:"fileRoot,omitempty"`
+
+	// HTTPOpenMethod is the HTTP verb used when opening HTTP and HTTPS resources
+	HTTPOpenMethod string `json:"httpOpenMethod,omitempty" yaml:"httpOpenMethod,omitempty"`
+
+	// HTTPHeaders are set on each request made for HTTP and HTTPS resources
+	HTTPHeaders http.Header `json:"httpHeaders,omitempty" yaml:"httpHeaders,omitempty"`
+
+	// HTTPClient is the client used for HTTP and HTTPS resources.  If not supplied, http.DefaultClient
+	// is used.  Since clients cannot be represented in configuration, this field is never marshaled.
+	HTTPClient HTTPClient `json:"-" yaml:"-"`
+
+	// Base64Encoding is the name of the encoding used for the BytesScheme resolver.  This must be one of
+	// StdEncoding, URLEncoding, RawStdEncoding, or RawURLEncoding.  If not supplied, StdEncoding is used.
+	Base64Encoding string `json:"base64Encoding,omitempty" yaml:"base64Encoding,omitempty"`
+}
+
+// EncodingError is returned when a SchemeResolverConfig names an unrecognized base64 encoding
+type EncodingError struct {
+	Encoding string
+}
+
+func (e EncodingError) Error() string {
+	return fmt.Sprintf("Unrecognized base64 encoding %s", e.Encoding)
+}
+
+func base64Encoding(name string) (*base64.Encoding, error) {
+	switch name {
+	case "", StdEncoding:
+		return base64.StdEncoding, nil
+	case URLEncoding:
+		return base64.URLEncoding, nil
+	case RawStdEncoding:
+		return base64.RawStdEncoding, nil
+	case RawURLEncoding:
+		return base64.RawURLEncoding, nil
+	default:
+		return nil, EncodingError{Encoding: name}
+	}
+}
+
+// NewSchemeResolver builds a SchemeResolver from a configuration.  The returned resolver has the same
+// scheme mappings as NewDefaultSchemeResolvers, with each component resolver customized by cfg.  Values
+// with no scheme are resolved as files relative to the configured FileRoot.
+func NewSchemeResolver(cfg SchemeResolverConfig) (SchemeResolver, error) {
+	enc, err := base64Encoding(cfg.Base64Encoding)
+	if err != nil {
+		return SchemeResolver{}, err
+	}
+
+	var client HTTPClient = http.DefaultClient
+	if cfg.HTTPClient != nil {
+		client = cfg.HTTPClient
+	}
+
+	var (
+		fr = FileResolver{Root: cfg.FileRoot}
+		hr = HTTPResolver{
+			OpenMethod: cfg.HTTPOpenMethod,
+			Client:     WithHeaders(cfg.HTTPHeaders, client),
+		}
+
+		rs = NewDefaultSchemeResolvers()
+	)
+
+	rs.Set(BytesScheme, BytesResolver{Encoding: enc})
+	rs.Set(FileScheme, fr)
+	rs.Set(HTTPScheme, hr)
+	rs.Set(HTTPSScheme, hr)
+
+	return SchemeResolver{
+		Resolvers: rs,
+		NoScheme:  fr,
+	}, nil
+}
+
 // SchemeError is returned when a scheme had no associated resolver.
 type SchemeError struct {
 	Value  string
@@ -61,6 +168,16 @@ func (e SchemeError) Error() string {
 	return fmt.Sprintf("Cannot resolve %s: no resolver registered for scheme %s", e.Value, e.Scheme)
 }
 
+// As allows errors.As to obtain a ResolveError from this error.  The ResolveError's Err is this error.
+func (e SchemeError) As(target interface{}) bool {
+	if re, ok := target.(*ResolveError); ok {
+		*re = ResolveError{Value: e.Value, Scheme: e.Scheme, Err: e}
+		return true
+	}
+
+	return false
+}
+
 // NoSchemeError is returned when no scheme was supplied on a resource and the SchemeResolver
 // had no NoScheme resolver configured.
 type NoSchemeError struct {
@@ -71,6 +188,110 @@ func (e NoSchemeError) Error() string {
 	return fmt.Sprintf("Cannot resolve %s: no scheme supplied", e.Value)
 }
 
+// As allows errors.As to obtain a ResolveError from this error.  The ResolveError's Err is this error.
+func (e NoSchemeError) As(target interface{}) bool {
+	if re, ok := target.(*ResolveError); ok {
+		*re = ResolveError{Value: e.Value, Err: e}
+		return true
+	}
+
+	return false
+}
+
+// EmptyValueError is returned when a resource value is empty or consists only of whitespace
+type EmptyValueError struct {
+	Value string
+}
+
+func (e EmptyValueError) Error() string {
+	return fmt.Sprintf("Cannot resolve %q: empty resource value", e.Value)
+}
+
+// WrongSchemeError is returned when a value does not have the scheme required by RequireScheme
+type WrongSchemeError struct {
+	Value    string
+	Expected string
+	Actual   string
+}
+
+func (e WrongSchemeError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: scheme %q is not permitted, expected %q", e.Value, e.Actual, e.Expected)
+}
+
+// RequireScheme decorates a Resolver so that only values with the given scheme are resolved.  Any other
+// value, including one with no scheme, results in a WrongSchemeError.  This acts as a guard rail for code
+// paths that must never accept, for example, a file:// value in place of an https:// one.
+func RequireScheme(scheme string, r Resolver) Resolver {
+	return ResolverFunc(func(v string) (Interface, error) {
+		if actual, _ := Split(v); actual != scheme {
+			return nil, WrongSchemeError{Value: v, Expected: scheme, Actual: actual}
+		}
+
+		return r.Resolve(v)
+	})
+}
+
+// InsecureSchemeError is returned by RequireTLSResolver when a value uses a plaintext network scheme
+type InsecureSchemeError struct {
+	Value  string
+	Scheme string
+}
+
+func (e InsecureSchemeError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: scheme %s is not encrypted", e.Value, e.Scheme)
+}
+
+// insecureSchemes are the plaintext network schemes rejected by RequireTLSResolver
+var insecureSchemes = []string{HTTPScheme, "ws", "ftp"}
+
+// RequireTLSResolver is a decorator that refuses to resolve values with plaintext network schemes, such as
+// http, which guards resources such as secrets against being fetched over an unencrypted connection due to
+// a typo.  Every other scheme, including HTTPSScheme, file, and in-memory schemes, is passed to the decorated
+// Resolver.
+//
+// For compound schemes such as "gzip+http", the base scheme, i.e. the last component, is checked.  Values
+// listing alternatives separated by DefaultFallbackSeparator are rejected if any alternative is insecure.
+// A FallbackSchemeResolver with a custom Separator, or any other decorator that rewrites values, must wrap
+// this resolver rather than be wrapped by it, so that each value is checked as it is finally resolved.
+type RequireTLSResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// AllowInsecure is the optional set of plaintext schemes that are permitted anyway, for data that is not sensitive
+	AllowInsecure []string
+}
+
+// insecure tests whether a scheme is plaintext and has not been explicitly allowed
+func (r RequireTLSResolver) insecure(scheme string) bool {
+	for _, insecure := range insecureSchemes {
+		if !strings.EqualFold(scheme, insecure) {
+			continue
+		}
+
+		for _, allowed := range r.AllowInsecure {
+			if strings.EqualFold(scheme, allowed) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (r RequireTLSResolver) Resolve(v string) (Interface, error) {
+	for _, alternative := range strings.Split(v, DefaultFallbackSeparator) {
+		scheme, _ := Split(alternative)
+		components := strings.Split(scheme, CompoundSchemeSeparator)
+		if base := components[len(components)-1]; r.insecure(base) {
+			return nil, InsecureSchemeError{Value: v, Scheme: base}
+		}
+	}
+
+	return r.Resolver.Resolve(v)
+}
+
 // SchemeResolver is a resource resolver that uses URI-style schemes to determine how to
 // resolve resources.  For example, "http://localhost/foo" is a resource value with the scheme "http".
 // Resource values resolved by this type of resolver do not have to be well-formed URIs unless the
@@ -79,21 +300,122 @@ func (e NoSchemeError) Error() string {
 type SchemeResolver struct {
 	Resolvers Resolvers
 	NoScheme  Resolver
+
+	// WrapErrors causes errors from component resolvers to be wrapped in a ResolveError, which reports
+	// the value and scheme involved.  Errors that already contain a ResolveError are returned as is.
+	// The original error remains available to errors.Is and errors.As.
+	WrapErrors bool
 }
 
-func (sr SchemeResolver) Resolve(v string) (Interface, error) {
+// wrap applies WrapErrors to an error from a component resolver
+func (sr SchemeResolver) wrap(v string, err error) error {
+	if err == nil || !sr.WrapErrors {
+		return err
+	}
+
+	var re ResolveError
+	if errors.As(err, &re) {
+		return err
+	}
+
+	return newResolveError(v, err)
+}
+
+// resolver selects the component resolver for a resource value
+func (sr SchemeResolver) resolver(v string) (Resolver, error) {
+	if len(strings.TrimSpace(v)) == 0 {
+		return nil, EmptyValueError{Value: v}
+	}
+
 	if scheme, _ := Split(v); len(scheme) > 0 {
 		resolver, ok := sr.Resolvers.Get(scheme)
 		if !ok {
 			return nil, SchemeError{Value: v, Scheme: scheme}
 		}
 
-		return resolver.Resolve(v)
+		return resolver, nil
 	}
 
 	if sr.NoScheme == nil {
 		return nil, NoSchemeError{Value: v}
 	}
 
-	return sr.NoScheme.Resolve(v)
+	return sr.NoScheme, nil
+}
+
+func (sr SchemeResolver) Resolve(v string) (Interface, error) {
+	resolver, err := sr.resolver(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := resolver.Resolve(v)
+	if err != nil {
+		return nil, sr.wrap(v, err)
+	}
+
+	return r, nil
+}
+
+// Validate checks v using the component resolver for its scheme
+func (sr SchemeResolver) Validate(v string) error {
+	resolver, err := sr.resolver(v)
+	if err != nil {
+		return err
+	}
+
+	return sr.wrap(v, Validate(resolver, v))
+}
+
+// CompoundSchemeSeparator separates the components of a compound scheme, such as "gzip+file"
+const CompoundSchemeSeparator = "+"
+
+// GzipScheme is the compound scheme component mapped to a GzipResolver by NewDefaultDecorators
+const GzipScheme = "gzip"
+
+// NewDefaultDecorators produces the default compound scheme decorators for a CompoundSchemeResolver.
+// GzipScheme is mapped to a GzipResolver.
+func NewDefaultDecorators() map[string]Middleware {
+	return map[string]Middleware{
+		GzipScheme: func(r Resolver) Resolver { return GzipResolver{Resolver: r} },
+	}
+}
+
+// CompoundSchemeResolver supports compound schemes that stack decorators onto a base scheme, such as
+// "gzip+file://data.json.gz".  The last component of a compound scheme is the base scheme, and the value
+// is passed to Resolver with only the base scheme, e.g. "file://data.json.gz".  Every other component names
+// a decorator, and decorators are applied left to right with the leftmost outermost.  For example,
+// "a+b+file://x" is resolved by a(b(Resolver)).
+//
+// Values without a compound scheme are passed to Resolver unchanged.
+type CompoundSchemeResolver struct {
+	// Resolver is the resolver for base schemes, typically a SchemeResolver.  This field is required.
+	Resolver Resolver
+
+	// Decorators maps compound scheme components onto functions that decorate a Resolver
+	Decorators map[string]Middleware
+}
+
+func (csr CompoundSchemeResolver) Resolve(v string) (Interface, error) {
+	scheme, value := Split(v)
+	components := strings.Split(scheme, CompoundSchemeSeparator)
+	if len(components) < 2 {
+		return csr.Resolver.Resolve(v)
+	}
+
+	var (
+		last     = len(components) - 1
+		resolver = csr.Resolver
+	)
+
+	for i := last - 1; i >= 0; i-- {
+		decorator, ok := csr.Decorators[components[i]]
+		if !ok {
+			return nil, SchemeError{Value: v, Scheme: components[i]}
+		}
+
+		resolver = decorator(resolver)
+	}
+
+	return resolver.Resolve(components[last] + SchemeSeparator + value)
 }
@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newTLSTestResolver produces a SchemeResolver for http and https values, along with a compound scheme
+// decorator named gzip that leaves the resolver unchanged.  https values whose host is "nope" fail to resolve.
+func newTLSTestResolver() CompoundSchemeResolver {
+	stub := ResolverFunc(func(v string) (Interface, error) {
+		if strings.HasPrefix(v, HTTPSScheme+SchemeSeparator+"nope") {
+			return nil, errors.New("expected")
+		}
+
+		return String(v), nil
+	})
+
+	return CompoundSchemeResolver{
+		Resolver: SchemeResolver{
+			Resolvers: Resolvers{HTTPScheme: stub, HTTPSScheme: stub},
+		},
+		Decorators: map[string]Middleware{
+			"gzip": func(next Resolver) Resolver { return next },
+		},
+	}
+}
+
+func TestRequireTLSResolver(t *testing.T) {
+	testData := []struct {
+		name     string
+		resolver Resolver
+		value    string
+		insecure string
+	}{
+		{"HTTPS", RequireTLSResolver{Resolver: newTLSTestResolver()}, "https://x", ""},
+		{"HTTP", RequireTLSResolver{Resolver: newTLSTestResolver()}, "http://x", HTTPScheme},
+		{"CompoundHTTPS", RequireTLSResolver{Resolver: newTLSTestResolver()}, "gzip+https://x", ""},
+		{"CompoundHTTP", RequireTLSResolver{Resolver: newTLSTestResolver()}, "gzip+http://x", HTTPScheme},
+		{"CompoundAllowed", RequireTLSResolver{Resolver: newTLSTestResolver(), AllowInsecure: []string{HTTPScheme}}, "gzip+http://x", ""},
+		{"FallbackHTTPS", RequireTLSResolver{Resolver: FallbackSchemeResolver{Resolver: newTLSTestResolver()}}, "https://nope||https://x", ""},
+		{"FallbackHTTP", RequireTLSResolver{Resolver: FallbackSchemeResolver{Resolver: newTLSTestResolver()}}, "https://nope||http://x", HTTPScheme},
+		{"FallbackCompoundHTTP", RequireTLSResolver{Resolver: FallbackSchemeResolver{Resolver: newTLSTestResolver()}}, "https://x||gzip+http://x", HTTPScheme},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			_, err := record.resolver.Resolve(record.value)
+			if len(record.insecure) == 0 {
+				if err != nil {
+					t.Errorf("Resolve failed: %s", err)
+				}
+
+				return
+			}
+
+			var ise InsecureSchemeError
+			if !errors.As(err, &ise) {
+				t.Fatalf("Expected an InsecureSchemeError, got %v", err)
+			}
+
+			if ise.Value != record.value || ise.Scheme != record.insecure {
+				t.Errorf("Unexpected InsecureSchemeError: %#v", ise)
+			}
+		})
+	}
+}
+
+func TestRequireTLSResolverInsideCustomFallback(t *testing.T) {
+	fsr := FallbackSchemeResolver{
+		Resolver:  RequireTLSResolver{Resolver: newTLSTestResolver()},
+		Separator: "|",
+	}
+
+	_, err := fsr.Resolve("https://nope|gzip+http://x")
+
+	var me MultiError
+	if !errors.As(err, &me) || len(me) != 2 {
+		t.Fatalf("Expected a MultiError with 2 errors, got %v", err)
+	}
+
+	var ise InsecureSchemeError
+	if !errors.As(me[1], &ise) || ise.Scheme != HTTPScheme {
+		t.Errorf("Expected the insecure alternative to be rejected, got %v", me[1])
+	}
+}
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"net/http"
+	"time"
+)
+
+// RequestSigner signs HTTP requests, typically by adding an Authorization header.  Signers for schemes such
+// as AWS Signature Version 4 can be adapted from their SDKs without this package depending on them.
+type RequestSigner interface {
+	// Sign modifies request so that it carries a valid signature.  An error prevents the request from being sent.
+	Sign(request *http.Request) error
+}
+
+// RequestSignerFunc is a function type that implements RequestSigner
+type RequestSignerFunc func(*http.Request) error
+
+func (rsf RequestSignerFunc) Sign(request *http.Request) error {
+	return rsf(request)
+}
+
+// WithSigner decorates an HTTPClient, signing each request immediately before it is sent.  Since signing happens
+// last, headers set by decorators wrapped by this one, i.e. applied later, are not covered by the signature.
+func WithSigner(signer RequestSigner, c HTTPClient) HTTPClient {
+	return HTTPClientFunc(func(request *http.Request) (*http.Response, error) {
+		if request.Header == nil {
+			request.Header = make(http.Header)
+		}
+
+		if err := signer.Sign(request); err != nil {
+			return nil, err
+		}
+
+		return c.Do(request)
+	})
+}
+
+// HMACSigner is a RequestSigner for simple shared-key schemes.  The string to sign is the request method, the
+// request URI (path and query), and the Date header, separated by newlines.  If the request has no Date header,
+// one is added with the current time.  The resulting header has the form:
+//
+//   Authorization: HMAC <KeyID>:<base64 signature>
+type HMACSigner struct {
+	// KeyID identifies the key to the server
+	KeyID string
+
+	// Key is the shared secret.  This field is required.
+	Key []byte
+
+	// Hash is the optional hash used for the HMAC.  If not supplied, sha256.New is used.
+	Hash func() hash.Hash
+}
+
+func (hs HMACSigner) Sign(request *http.Request) error {
+	date := request.Header.Get("Date")
+	if len(date) == 0 {
+		date = time.Now().UTC().Format(http.TimeFormat)
+		request.Header.Set("Date", date)
+	}
+
+	newHash := hs.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+
+	mac := hmac.New(newHash, hs.Key)
+	mac.Write([]byte(request.Method + "\n" + request.URL.RequestURI() + "\n" + date))
+	request.Header.Set("Authorization", "HMAC "+hs.KeyID+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return nil
+}
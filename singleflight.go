@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"sync"
+)
+
+// flight is a load of a single resource value that is in progress
+type flight struct {
+	done     chan struct{}
+	resource Interface
+	err      error
+}
+
+// SingleFlightResolver is a decorator that collapses concurrent resolutions of the same value into
+// a single load.  The first caller loads the resource through the decorated Resolver and buffers it in
+// memory, and all callers waiting on that value share the buffered result.  Nothing is retained once
+// the load completes, so a later resolution of the same value performs a new load.
+//
+// Since loading happens at resolution time, the handles returned by this resolver are always in-memory.
+type SingleFlightResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	lock    sync.Mutex
+	flights map[string]*flight
+}
+
+func (sfr *SingleFlightResolver) Resolve(v string) (Interface, error) {
+	sfr.lock.Lock()
+	if f, ok := sfr.flights[v]; ok {
+		sfr.lock.Unlock()
+		<-f.done
+		return f.resource, f.err
+	}
+
+	if sfr.flights == nil {
+		sfr.flights = make(map[string]*flight)
+	}
+
+	f := &flight{done: make(chan struct{})}
+	sfr.flights[v] = f
+	sfr.lock.Unlock()
+
+	var r Interface
+	if r, f.err = sfr.Resolver.Resolve(v); f.err == nil {
+		f.resource, f.err = Buffer(r)
+	}
+
+	sfr.lock.Lock()
+	delete(sfr.flights, v)
+	sfr.lock.Unlock()
+
+	close(f.done)
+	return f.resource, f.err
+}
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// DBScheme is the scheme conventionally mapped to a SQLResolver
+const DBScheme = "db"
+
+// SQL is a resource stored in a database, such as a BLOB column keyed by name.  The query is run
+// each time this resource is opened.  If the query reports sql.ErrNoRows, a NotFoundError is returned.
+type SQL struct {
+	// Key identifies the resource within the database
+	Key string
+
+	// Query loads the content for a key.  This field is required.
+	Query func(ctx context.Context, key string) ([]byte, error)
+}
+
+func (s SQL) Location() string {
+	return DBScheme + SchemeSeparator + s.Key
+}
+
+// load runs the query, translating a missing row into a NotFoundError
+func (s SQL) load(ctx context.Context) ([]byte, error) {
+	b, err := s.Query(ctx, s.Key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, NotFoundError{Value: s.Location()}
+	}
+
+	return b, err
+}
+
+// OpenContext runs the query using the given context, which may cancel it
+func (s SQL) OpenContext(ctx context.Context) (io.ReadCloser, error) {
+	b, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s SQL) Open() (io.ReadCloser, error) {
+	return s.OpenContext(context.Background())
+}
+
+func (s SQL) WriteTo(w io.Writer) (int64, error) {
+	b, err := s.load(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	return Bytes(b).WriteTo(w)
+}
+
+// SQLResolver resolves values such as "db://config-key" as content loaded from a database.  The query is
+// supplied as a function, so this package does not depend on any particular driver or schema.  Resolution
+// only captures the key.  The query runs when the returned SQL handle is opened.
+type SQLResolver struct {
+	// Query loads the content for a key.  It should return sql.ErrNoRows, or an error wrapping it, when
+	// the key does not exist.  This field is required.
+	Query func(ctx context.Context, key string) ([]byte, error)
+}
+
+func (r SQLResolver) Resolve(v string) (Interface, error) {
+	_, key := Split(v)
+	return SQL{Key: key, Query: r.Query}, nil
+}
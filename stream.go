@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// StreamScheme is the scheme conventionally mapped to a StreamResolver
+const StreamScheme = "stream"
+
+// StreamResolver resolves names, such as "stream://events", against a registry of stream factories.
+// Producers register a factory under a name, and consumers resolve that name like any other resource.
+// The returned handles are Func resources, so each Open or WriteTo invokes the factory for a fresh stream.
+// Any scheme on the value is ignored.  A StreamResolver is safe for concurrent use.
+type StreamResolver struct {
+	lock      sync.RWMutex
+	factories map[string]func() (io.ReadCloser, error)
+}
+
+// Register associates a stream factory with a name, replacing any existing factory for that name
+func (sr *StreamResolver) Register(name string, factory func() (io.ReadCloser, error)) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	if sr.factories == nil {
+		sr.factories = make(map[string]func() (io.ReadCloser, error))
+	}
+
+	sr.factories[name] = factory
+}
+
+// Unregister removes the factory for a name.  Handles already resolved for that name continue to work.
+func (sr *StreamResolver) Unregister(name string) {
+	sr.lock.Lock()
+	delete(sr.factories, name)
+	sr.lock.Unlock()
+}
+
+func (sr *StreamResolver) Resolve(v string) (Interface, error) {
+	_, name := Split(v)
+
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	factory, ok := sr.factories[name]
+	if !ok {
+		available := make([]string, 0, len(sr.factories))
+		for n := range sr.factories {
+			available = append(available, n)
+		}
+
+		sort.Strings(available)
+		return nil, UnknownNameError{Value: v, Name: name, Available: available}
+	}
+
+	return Func{Name: v, Source: factory}, nil
+}
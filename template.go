@@ -38,54 +38,104 @@ func ConfigureTemplateDefaults(t *template.Template) *template.Template {
 	return t.Funcs(template.FuncMap{DefaultEnvFunc: Getenv})
 }
 
+// ConfigureTemplateFuncs is like ConfigureTemplateDefaults, but also adds the given functions to the template.
+// The default functions are always present unless extra explicitly supplies a function with the same name,
+// e.g. DefaultEnvFunc, in which case the caller's function is used instead.
+func ConfigureTemplateFuncs(t *template.Template, extra template.FuncMap) *template.Template {
+	funcs := template.FuncMap{DefaultEnvFunc: Getenv}
+	for name, f := range extra {
+		funcs[name] = f
+	}
+
+	return t.Funcs(funcs)
+}
+
 // TemplateResolver is a decorator that expands resource strings as text templates and passes the
 // results to another Resolver.  An arbitrary template can be used for parsing, which allows customization
 // of delimiters, functions, etc.
+//
+// Values with the LiteralScheme, e.g. "literal://secret{{with}}://braces", are never expanded and are passed
+// to the decorated Resolver unchanged.  With the default scheme mappings, such values resolve to strings
+// containing everything after the scheme, verbatim.
 type TemplateResolver struct {
 	// Resolver is the decorated Resolver.  This resolver will receive expanded resource strings.
 	// This field is required.
 	Resolver Resolver
 
-	// Template is the optional template for parsing.  If supplied, this template's Parse method is used
-	// to expand resource strings.  If not supplied, a simple default template is created and used each
-	// time a resource string needs to be resolved.
-	//
-	// When supplied, this template's Parse method is guarded by an internal mutex.  Care must be taken that
-	// the same template is not used with multiple TemplateResolver instances.  It's safest to use template.Clone
-	// for each TemplateResolver's Template field, thus ensuring no race conditions will occur.
+	// Template is the optional template for parsing.  If supplied, this template is cloned each time a resource
+	// string needs to be resolved, and the clone's Parse method is used to expand it.  The supplied template
+	// itself is never parsed, executed, or otherwise modified, so it may be shared.  If not supplied, a simple
+	// default template is created and used each time a resource string needs to be resolved.
 	Template *template.Template
 
 	// Data is the optional data passed to each template execution.  If supplied, this value is passed as is
 	// to template.Execute.
 	Data interface{}
 
+	// StrictMissingKeys causes expansion to fail when a template refers to a map key that is not present in
+	// Data, rather than rendering "<no value>".  This sets the "missingkey=error" option on the template used
+	// for each expansion, which is never the supplied Template itself.
+	StrictMissingKeys bool
+
 	parseLock sync.Mutex
 }
 
 func (tr *TemplateResolver) parse(v string) (t *template.Template, err error) {
 	if tr.Template != nil {
 		tr.parseLock.Lock()
-		t, err = tr.Template.Parse(v)
+		t, err = tr.Template.Clone()
 		tr.parseLock.Unlock()
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		t, err = ConfigureTemplateDefaults(template.New("")).Parse(v)
+		t = ConfigureTemplateDefaults(template.New(""))
+	}
+
+	if tr.StrictMissingKeys {
+		t = t.Option("missingkey=error")
 	}
 
-	return
+	return t.Parse(v)
 }
 
-// Resolve expands v using the configured templating (or a default) and passes the result
-// to the decorated Resolver.
-func (tr *TemplateResolver) Resolve(v string) (Interface, error) {
+// expand executes v as a template, producing the resource string passed to the decorated Resolver.
+// Values with the LiteralScheme are returned as is.
+func (tr *TemplateResolver) expand(v string) (string, error) {
+	if scheme, _ := Split(v); scheme == LiteralScheme {
+		return v, nil
+	}
+
 	t, err := tr.parse(v)
 	if err != nil {
-		return nil, err
+		return "", newResolveError(v, err)
 	}
 
 	var output bytes.Buffer
 	if err := t.Execute(&output, tr.Data); err != nil {
+		return "", newResolveError(v, err)
+	}
+
+	return output.String(), nil
+}
+
+// Resolve expands v using the configured templating (or a default) and passes the result
+// to the decorated Resolver.
+func (tr *TemplateResolver) Resolve(v string) (Interface, error) {
+	expanded, err := tr.expand(v)
+	if err != nil {
 		return nil, err
 	}
 
-	return tr.Resolver.Resolve(output.String())
+	return tr.Resolver.Resolve(expanded)
+}
+
+// Validate expands v and validates the result using the decorated Resolver
+func (tr *TemplateResolver) Validate(v string) error {
+	expanded, err := tr.expand(v)
+	if err != nil {
+		return err
+	}
+
+	return Validate(tr.Resolver, expanded)
 }
@@ -0,0 +1,46 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"testing"
+)
+
+func TestTemplateResolverStrictMissingKeys(t *testing.T) {
+	supplied := ConfigureTemplateDefaults(template.New("").Delims("[[", "]]"))
+	resolver := &TemplateResolver{
+		Resolver:          ResolverFunc(func(v string) (Interface, error) { return String(v), nil }),
+		Template:          supplied,
+		Data:              map[string]string{"name": "config"},
+		StrictMissingKeys: true,
+	}
+
+	// resolving more than once requires that the supplied template is never executed directly
+	for i := 0; i < 2; i++ {
+		r, err := resolver.Resolve("[[.name]].json")
+		if err != nil {
+			t.Fatalf("Resolve failed: %s", err)
+		}
+
+		if actual := readAll(t, r); actual != "config.json" {
+			t.Errorf("Expected %q, got %q", "config.json", actual)
+		}
+	}
+
+	var re ResolveError
+	if _, err := resolver.Resolve("[[.missing]].json"); !errors.As(err, &re) || re.Value != "[[.missing]].json" {
+		t.Errorf("Expected a ResolveError for a missing key, got %v", err)
+	}
+
+	// the caller's template must not have picked up the missingkey option
+	t2, err := supplied.Parse("[[.missing]]")
+	if err != nil {
+		t.Fatalf("Unable to parse the supplied template: %s", err)
+	}
+
+	var output bytes.Buffer
+	if err := t2.Execute(&output, map[string]string{}); err != nil {
+		t.Errorf("The supplied template was modified to reject missing keys: %s", err)
+	}
+}
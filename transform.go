@@ -0,0 +1,396 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// readCloser combines an arbitrary io.Reader with the io.Closer of the stream it reads from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// transformed is a resource handle whose content is a transformation of another handle's content
+type transformed struct {
+	Interface
+	transform func(io.ReadCloser) (io.ReadCloser, error)
+}
+
+func (t transformed) Unwrap() Interface {
+	return t.Interface
+}
+
+func (t transformed) Open() (io.ReadCloser, error) {
+	rc, err := t.Interface.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	trc, err := t.transform(rc)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+
+	return trc, nil
+}
+
+func (t transformed) WriteTo(w io.Writer) (int64, error) {
+	rc, err := t.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeReader converts CRLF line endings to LF as the stream is read
+type normalizeReader struct {
+	br *bufio.Reader
+}
+
+func (nr normalizeReader) Read(p []byte) (int, error) {
+	n, err := nr.br.Read(p)
+	j := 0
+	for i := 0; i < n; i++ {
+		if p[i] == '\r' {
+			if i+1 < n {
+				if p[i+1] == '\n' {
+					continue
+				}
+			} else if next, peekErr := nr.br.Peek(1); peekErr == nil && next[0] == '\n' {
+				continue
+			}
+		}
+
+		p[j] = p[i]
+		j++
+	}
+
+	return j, err
+}
+
+// NormalizeResolver is a decorator that normalizes the content of the resources it resolves.
+// Transformations are applied as the content is streamed, so the content is never fully buffered.
+type NormalizeResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// CRLF enables conversion of CRLF line endings to LF
+	CRLF bool
+
+	// StripBOM enables removal of a leading UTF-8 byte order mark
+	StripBOM bool
+}
+
+func (nr NormalizeResolver) normalize(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	if nr.StripBOM {
+		if head, _ := br.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+			br.Discard(len(utf8BOM))
+		}
+	}
+
+	if nr.CRLF {
+		return readCloser{normalizeReader{br}, rc}, nil
+	}
+
+	return readCloser{br, rc}, nil
+}
+
+func (nr NormalizeResolver) Resolve(v string) (Interface, error) {
+	r, err := nr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if !nr.CRLF && !nr.StripBOM {
+		return r, nil
+	}
+
+	return transformed{Interface: r, transform: nr.normalize}, nil
+}
+
+// Encoding names for the byte order marks detected by TranscodeResolver
+const (
+	UTF8    = "UTF-8"
+	UTF16LE = "UTF-16LE"
+	UTF16BE = "UTF-16BE"
+	UTF32LE = "UTF-32LE"
+	UTF32BE = "UTF-32BE"
+)
+
+// boms lists the recognized byte order marks.  The UTF-32LE mark must be checked
+// before UTF-16LE, since the latter's mark is a prefix of the former.
+var boms = []struct {
+	encoding string
+	bom      []byte
+}{
+	{UTF8, utf8BOM},
+	{UTF32LE, []byte{0xFF, 0xFE, 0x00, 0x00}},
+	{UTF32BE, []byte{0x00, 0x00, 0xFE, 0xFF}},
+	{UTF16LE, []byte{0xFF, 0xFE}},
+	{UTF16BE, []byte{0xFE, 0xFF}},
+}
+
+// Decoder transforms a stream in some character encoding into UTF-8.  The *encoding.Decoder type
+// from golang.org/x/text/encoding implements this interface, so any of those decoders may be used
+// without this package depending on golang.org/x/text.
+type Decoder interface {
+	Reader(io.Reader) io.Reader
+}
+
+// DecoderFunc is a function type that implements Decoder
+type DecoderFunc func(io.Reader) io.Reader
+
+func (df DecoderFunc) Reader(r io.Reader) io.Reader {
+	return df(r)
+}
+
+// unicodeReader decodes fixed-width UTF-16 or UTF-32 code units into UTF-8
+type unicodeReader struct {
+	r     io.Reader
+	width int
+	order binary.ByteOrder
+	unit  [4]byte
+	out   bytes.Buffer
+	err   error
+
+	// pending is a code unit that was read ahead while looking for a low surrogate, but turned out not to be one
+	pending    rune
+	hasPending bool
+}
+
+func (ur *unicodeReader) next() (rune, bool) {
+	if _, err := io.ReadFull(ur.r, ur.unit[:ur.width]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			// a truncated code unit at the end of the stream
+			ur.out.WriteRune(utf8.RuneError)
+			err = io.EOF
+		}
+
+		ur.err = err
+		return 0, false
+	}
+
+	if ur.width == 2 {
+		return rune(ur.order.Uint16(ur.unit[:2])), true
+	}
+
+	return rune(ur.order.Uint32(ur.unit[:4])), true
+}
+
+func (ur *unicodeReader) decode() {
+	r, ok := ur.pending, ur.hasPending
+	if ok {
+		ur.hasPending = false
+	} else if r, ok = ur.next(); !ok {
+		return
+	}
+
+	// only a high surrogate starts a pair.  A lone low surrogate is invalid, and falls through to RuneError below.
+	if ur.width == 2 && r >= 0xD800 && r < 0xDC00 {
+		low, ok := ur.next()
+		if !ok {
+			ur.out.WriteRune(utf8.RuneError)
+			return
+		}
+
+		if low < 0xDC00 || low >= 0xE000 {
+			// an unpaired high surrogate: the following unit is decoded on its own
+			ur.pending, ur.hasPending = low, true
+			ur.out.WriteRune(utf8.RuneError)
+			return
+		}
+
+		r = utf16.DecodeRune(r, low)
+	}
+
+	if !utf8.ValidRune(r) {
+		r = utf8.RuneError
+	}
+
+	ur.out.WriteRune(r)
+}
+
+func (ur *unicodeReader) Read(p []byte) (int, error) {
+	for ur.out.Len() < len(p) && ur.err == nil {
+		ur.decode()
+	}
+
+	if ur.out.Len() > 0 {
+		return ur.out.Read(p)
+	}
+
+	return 0, ur.err
+}
+
+func unicodeDecoder(width int, order binary.ByteOrder) Decoder {
+	return DecoderFunc(func(r io.Reader) io.Reader {
+		return &unicodeReader{r: r, width: width, order: order}
+	})
+}
+
+var defaultDecoders = map[string]Decoder{
+	UTF16LE: unicodeDecoder(2, binary.LittleEndian),
+	UTF16BE: unicodeDecoder(2, binary.BigEndian),
+	UTF32LE: unicodeDecoder(4, binary.LittleEndian),
+	UTF32BE: unicodeDecoder(4, binary.BigEndian),
+}
+
+// TranscodeResolver is a decorator that converts text resources to UTF-8 based on a leading byte order mark.
+// UTF-8, UTF-16, and UTF-32 marks are recognized, and the mark itself is removed.  Content without a byte
+// order mark is assumed to be UTF-8 and passes through unchanged.  Transcoding happens as the content is streamed.
+type TranscodeResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+
+	// Decoders optionally overrides the decoders used for each encoding, keyed by names such as UTF16LE.
+	// Encodings not present in this map use the built-in decoders.
+	Decoders map[string]Decoder
+}
+
+func (tr TranscodeResolver) decoder(encoding string) Decoder {
+	if d, ok := tr.Decoders[encoding]; ok {
+		return d
+	}
+
+	return defaultDecoders[encoding]
+}
+
+func (tr TranscodeResolver) transcode(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	head, _ := br.Peek(4)
+	for _, b := range boms {
+		if bytes.HasPrefix(head, b.bom) {
+			br.Discard(len(b.bom))
+			if d := tr.decoder(b.encoding); d != nil {
+				return readCloser{d.Reader(br), rc}, nil
+			}
+
+			break
+		}
+	}
+
+	return readCloser{br, rc}, nil
+}
+
+func (tr TranscodeResolver) Resolve(v string) (Interface, error) {
+	r, err := tr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformed{Interface: r, transform: tr.transcode}, nil
+}
+
+// gzipReader closes both the gzip stream and the underlying compressed stream
+type gzipReader struct {
+	*gzip.Reader
+	rc io.ReadCloser
+}
+
+func (gr gzipReader) Close() error {
+	gr.Reader.Close()
+	return gr.rc.Close()
+}
+
+func gunzip(rc io.ReadCloser) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	return gzipReader{Reader: zr, rc: rc}, nil
+}
+
+// GzipResolver is a decorator for resources whose content is gzip-compressed.  The handles it returns
+// decompress the content as it is read.
+type GzipResolver struct {
+	// Resolver is the decorated Resolver.  This field is required.
+	Resolver Resolver
+}
+
+func (gr GzipResolver) Resolve(v string) (Interface, error) {
+	r, err := gr.Resolver.Resolve(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformed{Interface: r, transform: gunzip}, nil
+}
+
+// countingWriter counts the bytes written to an underlying writer
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.count += int64(n)
+	return n, err
+}
+
+type gzipped struct {
+	Interface
+	level int
+}
+
+func (g gzipped) Unwrap() Interface {
+	return g.Interface
+}
+
+// compress writes the gzip-compressed content of the decorated handle to w
+func (g gzipped) compress(w io.Writer) error {
+	zw, err := gzip.NewWriterLevel(w, g.level)
+	if err != nil {
+		return err
+	}
+
+	if _, err := g.Interface.WriteTo(zw); err != nil {
+		return err
+	}
+
+	// closing writes the gzip footer, without which the output is truncated
+	return zw.Close()
+}
+
+// Open returns a reader of the compressed content.  Compression happens in a separate goroutine as the
+// content is read, and closing the returned reader early stops it.
+func (g gzipped) Open() (io.ReadCloser, error) {
+	if _, err := gzip.NewWriterLevel(ioutil.Discard, g.level); err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(g.compress(pw))
+	}()
+
+	return pr, nil
+}
+
+// WriteTo compresses the content directly into w, returning the number of compressed bytes written
+func (g gzipped) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := g.compress(cw)
+	return cw.count, err
+}
+
+// GzipWriteTo decorates a resource handle so that its content is gzip-compressed at the given level, such as
+// gzip.BestCompression or gzip.DefaultCompression.  Both Open and WriteTo produce the compressed content,
+// which is streamed rather than buffered.  An invalid level results in an error from Open or WriteTo.
+func GzipWriteTo(r Interface, level int) Interface {
+	return gzipped{Interface: r, level: level}
+}
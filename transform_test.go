@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(order binary.ByteOrder, bom []byte, units ...uint16) []byte {
+	encoded := append([]byte(nil), bom...)
+	for _, u := range units {
+		var unit [2]byte
+		order.PutUint16(unit[:], u)
+		encoded = append(encoded, unit[:]...)
+	}
+
+	return encoded
+}
+
+func transcode(t *testing.T, content []byte) string {
+	tr := TranscodeResolver{
+		Resolver: ResolverFunc(func(string) (Interface, error) {
+			return Bytes(content), nil
+		}),
+	}
+
+	r, err := tr.Resolve("content")
+	if err != nil {
+		t.Fatalf("Resolve failed: %s", err)
+	}
+
+	rc, err := r.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %s", err)
+	}
+
+	return string(data)
+}
+
+func TestTranscodeResolverUTF16(t *testing.T) {
+	const text = "héllo, wörld 😀"
+	testData := []struct {
+		name  string
+		order binary.ByteOrder
+		bom   []byte
+	}{
+		{UTF16LE, binary.LittleEndian, []byte{0xFF, 0xFE}},
+		{UTF16BE, binary.BigEndian, []byte{0xFE, 0xFF}},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			content := encodeUTF16(record.order, record.bom, utf16.Encode([]rune(text))...)
+			if actual := transcode(t, content); actual != text {
+				t.Errorf("Expected %q, got %q", text, actual)
+			}
+		})
+	}
+}
+
+func TestTranscodeResolverUnpairedSurrogates(t *testing.T) {
+	testData := []struct {
+		name     string
+		units    []uint16
+		expected string
+	}{
+		{"HighThenBMP", []uint16{0xD83D, 'A', 'B'}, "�AB"},
+		{"HighThenHigh", []uint16{0xD83D, 0xD83D, 0xDE00}, "�😀"},
+		{"LoneLow", []uint16{'A', 0xDE00, 'B'}, "A�B"},
+		{"HighAtEnd", []uint16{'A', 0xD83D}, "A�"},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			content := encodeUTF16(binary.LittleEndian, []byte{0xFF, 0xFE}, record.units...)
+			if actual := transcode(t, content); actual != record.expected {
+				t.Errorf("Expected %q, got %q", record.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTranscodeResolverNoBOM(t *testing.T) {
+	if actual := transcode(t, []byte("plain text")); actual != "plain text" {
+		t.Errorf("Expected content without a byte order mark to pass through, got %q", actual)
+	}
+}
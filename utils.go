@@ -1,18 +1,47 @@
 package resource
 
-var defaultResolver Resolver = &TemplateResolver{
-	Resolver: SchemeResolver{
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var (
+	defaultSchemeResolver Resolver = SchemeResolver{
 		Resolvers: NewDefaultSchemeResolvers(),
 		NoScheme:  FileResolver{},
-	},
-}
+	}
+
+	defaultResolver Resolver = &TemplateResolver{
+		Resolver: defaultSchemeResolver,
+	}
+)
 
 // DefaultResolver returns the default Resolver implementation, which is a TemplateResolver that
-// delegates to a default SchemeResolver.
+// delegates to a default SchemeResolver.  Every value is expanded as a template before it is resolved,
+// so values containing template syntax such as "{{" must either be valid templates or use the LiteralScheme.
+//
+// To customize the default behavior, build a SchemeResolver from NewDefaultSchemeResolvers or
+// NewSchemeResolver and optionally decorate it with a TemplateResolver.
 func DefaultResolver() Resolver {
 	return defaultResolver
 }
 
+// DefaultResolverNoTemplate returns the same scheme resolution as DefaultResolver, but without any
+// template expansion.  Values are resolved exactly as given.  Use this resolver when values may legitimately
+// contain template syntax, or when templating is simply not wanted.
+func DefaultResolverNoTemplate() Resolver {
+	return defaultSchemeResolver
+}
+
 // Must panics if err != nil, returning the given resource handle otherwise
 func Must(r Interface, err error) Interface {
 	if err != nil {
@@ -21,3 +50,412 @@ func Must(r Interface, err error) Interface {
 
 	return r
 }
+
+// FromReader reads the given io.Reader fully, returning its contents as an in-memory resource
+func FromReader(r io.Reader) (Bytes, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Bytes(b), nil
+}
+
+// FromReadCloser is like FromReader, except that the given io.ReadCloser is always closed
+func FromReadCloser(rc io.ReadCloser) (Bytes, error) {
+	defer rc.Close()
+	return FromReader(rc)
+}
+
+// ResolveOptional resolves a resource that is allowed to be absent.  It returns the handle and true if the resource
+// exists, false with a nil error if it does not, and the error for any other failure.  A resource is absent when
+// the error satisfies errors.Is(err, ErrNotFound).  If r implements Validator, v is validated first, so that
+// missing files and similar resources are detected without being opened.
+func ResolveOptional(r Resolver, v string) (Interface, bool, error) {
+	h, err := resolveValidated(r, v)
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return h, true, nil
+}
+
+// SaveTo writes the contents of a resource to the file at the given path, creating any parent
+// directories as necessary.  An existing file at path is truncated.  The number of bytes written
+// is returned, along with any error from writing or closing the file.
+func SaveTo(r Interface, path string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.WriteTo(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return count, err
+}
+
+// AtomicSaveTo is like SaveTo, but never leaves a partially written file at path.  The contents are written
+// and synced to a temporary file in the same directory, which is then renamed into place, so that path holds
+// either its previous contents or the complete new contents.  The temporary file is removed on any error.
+// The resulting file has mode 0644.
+//
+// If the rename fails because the temporary file and path are on different devices, as can happen with
+// bind mounts, the contents are copied into path instead.  That fallback is not atomic.
+func AtomicSaveTo(r Interface, path string) (int64, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return 0, err
+	}
+
+	temp := f.Name()
+	defer os.Remove(temp)
+
+	count, err := r.WriteTo(f)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	err = os.Rename(temp, path)
+	if errors.Is(err, syscall.EXDEV) {
+		_, err = SaveTo(File(temp), path)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SaveToTemp writes the contents of a resource to a new temporary file.  The returned cleanup function
+// removes the temporary file, and is safe to call more than once.  On error, no temporary file is left behind.
+func SaveToTemp(r Interface) (path string, cleanup func(), err error) {
+	f, err := ioutil.TempFile("", "resource-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	path = f.Name()
+	cleanup = func() { os.Remove(path) }
+
+	_, err = r.WriteTo(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return path, cleanup, nil
+}
+
+// CopyContext copies the contents of a resource to dst, stopping with ctx.Err() if ctx is done before the copy
+// completes.  The optional onProgress callback is invoked with the total number of bytes copied so far after
+// each chunk is written.  In-memory resources are copied with a single WriteTo, since there is nothing to cancel.
+// Other resources are opened with OpenContext and copied in chunks, with ctx checked before each chunk.
+func CopyContext(ctx context.Context, dst io.Writer, r Interface, onProgress func(int64)) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	switch r.(type) {
+	case String, Bytes, located:
+		count, err := r.WriteTo(dst)
+		if onProgress != nil && count > 0 {
+			onProgress(count)
+		}
+
+		return count, err
+	}
+
+	rc, err := OpenContext(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+
+	var (
+		buffer = make([]byte, 32*1024)
+		total  int64
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, readErr := rc.Read(buffer)
+		if n > 0 {
+			written, err := dst.Write(buffer[:n])
+			total += int64(written)
+			if err == nil && written < n {
+				err = io.ErrShortWrite
+			}
+
+			if err != nil {
+				return total, err
+			}
+
+			if onProgress != nil {
+				onProgress(total)
+			}
+		}
+
+		if readErr == io.EOF {
+			return total, nil
+		} else if readErr != nil {
+			return total, readErr
+		}
+	}
+}
+
+// WriteToBuffer resets the given buffer and writes the contents of a resource into it, allowing
+// callers to control allocation when loading many resources.  For in-memory resources, the buffer
+// is grown once to the known size before writing.
+//
+// This function pairs naturally with a sync.Pool of *bytes.Buffer:
+//
+//   buf := pool.Get().(*bytes.Buffer)
+//   defer pool.Put(buf)
+//   if _, err := resource.WriteToBuffer(r, buf); err != nil {
+//     // handle the error
+//   }
+func WriteToBuffer(r Interface, buf *bytes.Buffer) (int64, error) {
+	buf.Reset()
+	switch v := r.(type) {
+	case String:
+		buf.Grow(len(v))
+	case Bytes:
+		buf.Grow(len(v))
+	}
+
+	return r.WriteTo(buf)
+}
+
+// located is an in-memory resource that reports an arbitrary location
+type located struct {
+	Bytes
+	location string
+}
+
+func (l located) Location() string {
+	return l.location
+}
+
+// Buffer reads a resource once, producing an in-memory resource that can be read any number of times.
+// The returned handle reports the same Location as the original resource.
+func Buffer(r Interface) (Interface, error) {
+	var output bytes.Buffer
+	if _, err := r.WriteTo(&output); err != nil {
+		return nil, err
+	}
+
+	return located{Bytes: Bytes(output.Bytes()), location: r.Location()}, nil
+}
+
+// EnsureRereadable returns a handle that can be read any number of times.  If r is Rereadable, it is
+// returned as is.  Otherwise, r is read once and buffered in memory.
+func EnsureRereadable(r Interface) (Interface, error) {
+	if IsRereadable(r) {
+		return r, nil
+	}
+
+	return Buffer(r)
+}
+
+// peeked is a resource whose first Open replays bytes already read from the original resource,
+// followed by the remainder of the original stream.  Subsequent opens reopen the original resource.
+type peeked struct {
+	Interface
+
+	lock sync.Mutex
+	head []byte
+	rc   io.ReadCloser
+}
+
+func (p *peeked) Unwrap() Interface {
+	return p.Interface
+}
+
+func (p *peeked) Open() (io.ReadCloser, error) {
+	p.lock.Lock()
+	rc := p.rc
+	p.rc = nil
+	p.lock.Unlock()
+
+	if rc == nil {
+		return p.Interface.Open()
+	}
+
+	return readCloser{io.MultiReader(bytes.NewReader(p.head), rc), rc}, nil
+}
+
+func (p *peeked) WriteTo(w io.Writer) (int64, error) {
+	rc, err := p.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// Peek reads up to n leading bytes of a resource, e.g. for content sniffing, without losing them.
+// The returned rest handle produces the resource's full content, including the peeked bytes.
+// Rereadable resources, such as in-memory and file resources, are simply reopened, so for those rest is
+// r itself.  For other resources, the first Open of rest continues the stream used for peeking, so it must
+// eventually be opened and closed.
+func Peek(r Interface, n int) (head []byte, rest Interface, err error) {
+	rc, err := r.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	head = make([]byte, n)
+	count, err := io.ReadFull(rc, head)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	head = head[:count]
+	if err != nil {
+		rc.Close()
+		return nil, nil, err
+	}
+
+	if IsRereadable(r) {
+		rc.Close()
+		return head, r, nil
+	}
+
+	return head, &peeked{Interface: r, head: head, rc: rc}, nil
+}
+
+// MapEntryError describes an entry of a ResolveMap specification that could not be parsed or resolved
+type MapEntryError struct {
+	Name string
+	Err  error
+}
+
+func (e MapEntryError) Error() string {
+	return fmt.Sprintf("Cannot resolve map entry %q: %s", e.Name, e.Err)
+}
+
+func (e MapEntryError) Unwrap() error {
+	return e.Err
+}
+
+// splitMapSpec parses a ResolveMap specification into its name/value pairs, processing escapes
+func splitMapSpec(spec string) (names, values []string, err error) {
+	var (
+		current strings.Builder
+		name    string
+		hasName bool
+	)
+
+	finish := func() error {
+		if !hasName {
+			return MapEntryError{Name: current.String(), Err: errors.New("missing '='")}
+		}
+
+		if len(name) == 0 {
+			return MapEntryError{Err: errors.New("empty name")}
+		}
+
+		names, values = append(names, name), append(values, current.String())
+		current.Reset()
+		hasName = false
+		return nil
+	}
+
+	for i := 0; i < len(spec); i++ {
+		switch c := spec[i]; {
+		case c == '\\' && i+1 < len(spec):
+			i++
+			current.WriteByte(spec[i])
+
+		case c == '=' && !hasName:
+			name, hasName = current.String(), true
+			current.Reset()
+
+		case c == ',':
+			if err := finish(); err != nil {
+				return nil, nil, err
+			}
+
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if err := finish(); err != nil {
+		return nil, nil, err
+	}
+
+	return names, values, nil
+}
+
+// ResolveMap resolves a specification of named resources, such as "a=file://a.json,b=https://host/b", into
+// a map of handles keyed by name.  Entries are separated by commas, and each entry's name is separated from its
+// resource string by the first equals sign.  Within names and resource strings, a backslash escapes the following
+// character, so "\," is a literal comma, "\=" is a literal equals sign, and "\\" is a literal backslash.  Since
+// only the first equals sign separates the name, resource strings may otherwise contain equals signs unescaped.
+// A backslash at the very end of the specification has nothing to escape and is kept as a literal backslash.
+//
+// An empty specification produces an empty map.  Resolution stops at the first entry that is malformed,
+// duplicated, or fails to resolve, which is reported as a MapEntryError naming that entry.
+func ResolveMap(r Resolver, spec string) (map[string]Interface, error) {
+	resources := make(map[string]Interface)
+	if len(spec) == 0 {
+		return resources, nil
+	}
+
+	names, values, err := splitMapSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range names {
+		if _, exists := resources[name]; exists {
+			return nil, MapEntryError{Name: name, Err: errors.New("duplicate name")}
+		}
+
+		h, err := r.Resolve(values[i])
+		if err != nil {
+			return nil, MapEntryError{Name: name, Err: err}
+		}
+
+		resources[name] = h
+	}
+
+	return resources, nil
+}
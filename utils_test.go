@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveMap(t *testing.T) {
+	testData := []struct {
+		name     string
+		spec     string
+		expected map[string]string
+		invalid  string
+	}{
+		{"Empty", "", map[string]string{}, ""},
+		{"Single", "a=file://a.json", map[string]string{"a": "file://a.json"}, ""},
+		{"Multiple", "a=file://a.json,b=https://host/b", map[string]string{"a": "file://a.json", "b": "https://host/b"}, ""},
+		{"EscapedComma", `a=x\,y,b=z`, map[string]string{"a": "x,y", "b": "z"}, ""},
+		{"EscapedEquals", `a\=b=c`, map[string]string{"a=b": "c"}, ""},
+		{"EscapedBackslash", `a=x\\,b=y`, map[string]string{"a": `x\`, "b": "y"}, ""},
+		{"EscapedLetter", `a=\x`, map[string]string{"a": "x"}, ""},
+		{"TrailingBackslash", `a=x\`, map[string]string{"a": `x\`}, ""},
+		{"EqualsInValue", "a=https://host/b?x=1&y=2", map[string]string{"a": "https://host/b?x=1&y=2"}, ""},
+		{"EmptyValue", "a=", map[string]string{"a": ""}, ""},
+		{"TrailingComma", "a=x,", nil, ""},
+		{"MissingEquals", "a=x,b", nil, "b"},
+		{"EmptyName", "=x", nil, ""},
+		{"DuplicateName", "a=x,b=y,a=z", nil, "a"},
+		{"Unresolvable", "a=x,b=fail", nil, "b"},
+	}
+
+	r := ResolverFunc(func(v string) (Interface, error) {
+		if v == "fail" {
+			return nil, errors.New("expected")
+		}
+
+		return String(v), nil
+	})
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			resources, err := ResolveMap(r, record.spec)
+			if record.expected == nil {
+				var mee MapEntryError
+				if !errors.As(err, &mee) {
+					t.Fatalf("Expected a MapEntryError, got %v", err)
+				}
+
+				if mee.Name != record.invalid {
+					t.Errorf("Expected the error to name entry %q, got %q", record.invalid, mee.Name)
+				}
+
+				if resources != nil {
+					t.Errorf("Expected no resources alongside an error, got %v", resources)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ResolveMap failed: %s", err)
+			}
+
+			actual := make(map[string]string, len(resources))
+			for name, h := range resources {
+				actual[name] = readAll(t, h)
+			}
+
+			if !reflect.DeepEqual(actual, record.expected) {
+				t.Errorf("Expected %q, got %q", record.expected, actual)
+			}
+		})
+	}
+}
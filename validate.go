@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"strings"
+)
+
+// Validator is an optional interface implemented by resolvers that can check a resource value
+// without loading the resource.  This is useful for verifying configuration at startup.
+type Validator interface {
+	// Validate checks that v can be resolved, returning an error describing the problem if it cannot.
+	// The checks performed depend on the resolver.  For example, a FileResolver checks that the file exists.
+	Validate(v string) error
+}
+
+// Validate checks v using the given resolver.  If r implements Validator, its Validate method is used.
+// Otherwise, v is simply resolved and the handle is discarded.
+func Validate(r Resolver, v string) error {
+	if validator, ok := r.(Validator); ok {
+		return validator.Validate(v)
+	}
+
+	_, err := r.Resolve(v)
+	return err
+}
+
+// resolveValidated resolves v, first checking it with Validate if r implements Validator.  This surfaces
+// errors, such as a missing file, that some resolvers would otherwise only report once the resource is opened.
+func resolveValidated(r Resolver, v string) (Interface, error) {
+	if validator, ok := r.(Validator); ok {
+		if err := validator.Validate(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.Resolve(v)
+}
+
+// MultiError is a collection of errors reported together
+type MultiError []error
+
+func (me MultiError) Error() string {
+	messages := make([]string, len(me))
+	for i, err := range me {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// ValidateAll validates each value with the given resolver.  All values are checked, and any
+// failures are reported together as a MultiError.  If all values are valid, this function returns nil.
+func ValidateAll(r Resolver, values []string) error {
+	var me MultiError
+	for _, v := range values {
+		if err := Validate(r, v); err != nil {
+			me = append(me, err)
+		}
+	}
+
+	if len(me) > 0 {
+		return me
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// VaultScheme is the scheme conventionally mapped to a VaultResolver
+const VaultScheme = "vault"
+
+// VaultClient is the minimal behavior required to read secrets from HashiCorp Vault.  Implementations
+// typically adapt the Logical().ReadWithContext method of the Vault SDK, and are responsible for all
+// authentication concerns, including token renewal.
+type VaultClient interface {
+	// Read returns the data stored at a path.  If nothing exists at that path, Read should return
+	// a nil map and a nil error.
+	Read(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// MissingFieldError is returned when a secret exists but does not contain the requested field
+type MissingFieldError struct {
+	Value string
+	Field string
+}
+
+func (e MissingFieldError) Error() string {
+	return fmt.Sprintf("Cannot resolve %s: no field named %s", e.Value, e.Field)
+}
+
+// VaultResolver resolves secrets stored in HashiCorp Vault.  Values have the form
+// "vault://secret/data/myapp#password", where the optional fragment selects a single field of the secret.
+// For secrets stored with version 2 of the KV engine, fields are also looked up within the nested
+// "data" map.  Without a fragment, the entire secret is returned as JSON.
+//
+// Secrets are read at resolution time, and the returned handles are in-memory.
+type VaultResolver struct {
+	// Client is the Vault client used to read secrets.  This field is required.
+	Client VaultClient
+}
+
+// vaultValue converts a secret's value into an in-memory resource
+func vaultValue(v interface{}) (Interface, error) {
+	switch value := v.(type) {
+	case string:
+		return String(value), nil
+	case []byte:
+		return Bytes(value), nil
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return Bytes(b), nil
+	}
+}
+
+func (vr VaultResolver) Resolve(v string) (Interface, error) {
+	_, path := Split(v)
+	field := ""
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path, field = path[:i], path[i+1:]
+	}
+
+	secret, err := vr.Client.Read(context.Background(), path)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	if secret == nil {
+		return nil, NotFoundError{Value: v}
+	}
+
+	var (
+		selected interface{} = secret
+		ok                   = true
+	)
+
+	if len(field) > 0 {
+		if selected, ok = secret[field]; !ok {
+			if data, isMap := secret["data"].(map[string]interface{}); isMap {
+				selected, ok = data[field]
+			}
+		}
+	}
+
+	if !ok {
+		return nil, MissingFieldError{Value: v, Field: field}
+	}
+
+	r, err := vaultValue(selected)
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return r, nil
+}
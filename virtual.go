@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"io"
+	"sync"
+)
+
+// VirtualSource produces the content for a resource value, along with its size or -1 if the size is not known.
+// If there is no content for the value, it should return an error for which errors.Is(err, ErrNotFound) is true.
+type VirtualSource func(v string) (io.ReadCloser, int64, error)
+
+// Virtual is a resource whose content is produced by a VirtualSource.  The stream obtained when the value was
+// resolved is used by the first Open or WriteTo, and each later one invokes the source again.
+type Virtual struct {
+	value  string
+	source VirtualSource
+	size   int64
+
+	lock sync.Mutex
+	rc   io.ReadCloser
+}
+
+func (vr *Virtual) Location() string {
+	return vr.value
+}
+
+// Size returns the size reported by the source when the value was resolved, or -1 if it was not known
+func (vr *Virtual) Size() int64 {
+	return vr.size
+}
+
+func (vr *Virtual) Open() (io.ReadCloser, error) {
+	vr.lock.Lock()
+	rc := vr.rc
+	vr.rc = nil
+	vr.lock.Unlock()
+
+	if rc != nil {
+		return rc, nil
+	}
+
+	rc, _, err := vr.source(vr.value)
+	return rc, err
+}
+
+func (vr *Virtual) WriteTo(w io.Writer) (int64, error) {
+	rc, err := vr.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// VirtualResolver resolves values through a single function, which makes it the simplest way to back an
+// arbitrary scheme with custom content.  The source is invoked when a value is resolved, so that errors such
+// as missing content surface immediately, and the returned *Virtual handles implement Sized.  If a handle is
+// never opened, the stream obtained during resolution is never closed, so sources that hold scarce resources,
+// such as file descriptors, should defer acquiring them until the first Read.
+type VirtualResolver struct {
+	// Source produces the content for each value.  This field is required.
+	Source VirtualSource
+}
+
+func (r VirtualResolver) Resolve(v string) (Interface, error) {
+	rc, size, err := r.Source(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if size < 0 {
+		size = -1
+	}
+
+	return &Virtual{value: v, source: r.Source, size: size, rc: rc}, nil
+}
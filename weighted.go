@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// ErrNoWeightedSources is returned by a WeightedResolver that has no source with a positive weight
+var ErrNoWeightedSources = errors.New("No weighted sources with a positive weight")
+
+// WeightedSource is a resource string along with its relative likelihood of being chosen
+type WeightedSource struct {
+	Value  string
+	Weight int
+}
+
+// WeightedResolver chooses among several sources at random, in proportion to their weights, e.g. to roll out
+// a new configuration to a fraction of instances.  Each resolution makes an independent choice and resolves the
+// chosen source's value through Resolver.  The value passed to Resolve is ignored.  Sources with nonpositive
+// weights are never chosen.
+type WeightedResolver struct {
+	// Resolver resolves the chosen source's value.  This field is required.
+	Resolver Resolver
+
+	// Sources are the candidates to choose among
+	Sources []WeightedSource
+
+	// Intn is the optional source of randomness, which must behave like rand.Intn.  If not supplied,
+	// rand.Intn is used.  Supplying a seeded generator makes choices deterministic.
+	Intn func(int) int
+}
+
+// choose selects a source by weight
+func (wr WeightedResolver) choose() (WeightedSource, error) {
+	total := 0
+	for _, s := range wr.Sources {
+		if s.Weight > 0 {
+			total += s.Weight
+		}
+	}
+
+	if total == 0 {
+		return WeightedSource{}, ErrNoWeightedSources
+	}
+
+	intn := wr.Intn
+	if intn == nil {
+		intn = rand.Intn
+	}
+
+	n := intn(total)
+	for _, s := range wr.Sources {
+		if s.Weight <= 0 {
+			continue
+		}
+
+		if n < s.Weight {
+			return s, nil
+		}
+
+		n -= s.Weight
+	}
+
+	// unreachable as long as Intn returns a value in [0, total)
+	return WeightedSource{}, ErrNoWeightedSources
+}
+
+func (wr WeightedResolver) Resolve(v string) (Interface, error) {
+	s, err := wr.choose()
+	if err != nil {
+		return nil, newResolveError(v, err)
+	}
+
+	return wr.Resolver.Resolve(s.Value)
+}